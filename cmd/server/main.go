@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,12 +12,15 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/pressly/goose/v3"
 	"github.com/yeboahd24/authentication/internal/api"
 	"github.com/yeboahd24/authentication/internal/config"
 	"github.com/yeboahd24/authentication/internal/db"
 	sqlc "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/jobs"
 	"github.com/yeboahd24/authentication/internal/service"
+	"github.com/yeboahd24/authentication/internal/storage"
 )
 
 func runDBMigrations(db *sql.DB, migrationsDir string) error {
@@ -54,6 +58,17 @@ func main() {
 
 	queries := sqlc.New(database)
 
+	// Accounts created before confusable detection have no skeleton yet
+	if err := jobs.BackfillUsernameSkeletons(context.Background(), queries); err != nil {
+		log.Fatalf("Failed to backfill username skeletons: %v", err)
+	}
+
+	switch dbConfig.Registration.Mode {
+	case config.RegistrationOpen, config.RegistrationInvite, config.RegistrationClosed:
+	default:
+		log.Fatalf("Unknown registration mode %q", dbConfig.Registration.Mode)
+	}
+
 	jwtMaker := service.NewJWTMaker(dbConfig.JWT.SecretKey)
 
 	emailService := service.NewEmailService(service.EmailConfig{
@@ -62,15 +77,136 @@ func main() {
 		Username: dbConfig.Email.Username,
 		Password: dbConfig.Email.Password,
 		From:     dbConfig.Email.From,
+		BaseURL:  dbConfig.Email.BaseURL,
+	})
+
+	var smsSender service.SMSSender
+	switch dbConfig.SMS.Provider {
+	case "http":
+		smsSender = service.NewHTTPSMSSender(service.HTTPSMSConfig{
+			URL:     dbConfig.SMS.HTTP.URL,
+			APIKey:  os.Getenv(dbConfig.SMS.HTTP.APIKeyEnv),
+			Timeout: dbConfig.SMS.HTTP.Timeout,
+		})
+	case "file", "":
+		smsSender = service.NewFileSMSSender(dbConfig.SMS.File)
+	default:
+		log.Fatalf("Unknown SMS provider %q", dbConfig.SMS.Provider)
+	}
+	smsService := service.NewSMSService(smsSender, dbConfig.SMS.From)
+
+	passwordPolicy, err := service.NewPasswordPolicy(service.PasswordPolicyConfig{
+		Version:         dbConfig.PasswordPolicy.Version,
+		MinLength:       dbConfig.PasswordPolicy.MinLength,
+		MaxLength:       dbConfig.PasswordPolicy.MaxLength,
+		RequireUpper:    dbConfig.PasswordPolicy.RequireUpper,
+		RequireLower:    dbConfig.PasswordPolicy.RequireLower,
+		RequireDigit:    dbConfig.PasswordPolicy.RequireDigit,
+		RequireSpecial:  dbConfig.PasswordPolicy.RequireSpecial,
+		BannedWordsFile: dbConfig.PasswordPolicy.BannedWordsFile,
+		AllowUserInfo:   dbConfig.PasswordPolicy.AllowUserInfo,
+		MinStrength:     dbConfig.PasswordPolicy.MinStrength,
+		HistorySize:     dbConfig.PasswordPolicy.HistorySize,
+		MaxAge:          dbConfig.PasswordPolicy.MaxAge,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+
+	emailPolicy, err := service.NewEmailPolicy(service.EmailPolicyConfig{
+		AllowedDomains:        dbConfig.EmailPolicy.AllowedDomains,
+		BlockedDomains:        dbConfig.EmailPolicy.BlockedDomains,
+		BlockDisposable:       dbConfig.EmailPolicy.BlockDisposable,
+		DisposableDomainsFile: dbConfig.EmailPolicy.DisposableDomainsFile,
+		CheckMX:               dbConfig.EmailPolicy.CheckMX,
+		MXTimeout:             dbConfig.EmailPolicy.MXTimeout,
+	}, net.DefaultResolver)
+	if err != nil {
+		log.Fatalf("Failed to load email policy: %v", err)
+	}
+
+	usernamePolicy := service.NewUsernamePolicy(service.UsernamePolicyConfig{
+		MinLength:          dbConfig.UsernamePolicy.MinLength,
+		MaxLength:          dbConfig.UsernamePolicy.MaxLength,
+		AllowUnicode:       dbConfig.UsernamePolicy.AllowUnicode,
+		AllowedPunctuation: dbConfig.UsernamePolicy.AllowedPunctuation,
+	})
+
+	pepperKeys := make(map[int][]byte)
+	for _, keyConfig := range dbConfig.PasswordPepper.Keys {
+		key, err := service.LoadPepperKey(keyConfig.File, keyConfig.Env)
+		if err != nil {
+			if keyConfig.Version == dbConfig.PasswordPepper.CurrentVersion {
+				log.Fatalf("Failed to load current password pepper key: %v", err)
+			}
+			log.Printf("Skipping password pepper key version %d: %v", keyConfig.Version, err)
+			continue
+		}
+		pepperKeys[keyConfig.Version] = key
+	}
+
+	passwordConfig, err := service.NewPasswordConfig().WithPepper(dbConfig.PasswordPepper.CurrentVersion, pepperKeys)
+	if err != nil {
+		log.Fatalf("Failed to configure password pepper: %v", err)
+	}
+
+	files, err := storage.NewLocalStorage(dbConfig.Storage.Dir)
+	if err != nil {
+		log.Fatalf("Failed to set up file storage: %v", err)
+	}
+
+	avatars := service.NewAvatarProcessor(service.AvatarConfig{
+		MaxBytes:  dbConfig.Avatar.MaxBytes,
+		MaxPixels: dbConfig.Avatar.MaxPixels,
+		Sizes:     dbConfig.Avatar.Sizes,
+	})
+
+	// Authenticator app 2FA stays off rather than storing secrets unencrypted
+	var totp *service.TOTP
+	totpKey, err := service.LoadPepperKey(dbConfig.TOTP.KeyFile, dbConfig.TOTP.KeyEnv)
+	if err == nil {
+		totp, err = service.NewTOTP(dbConfig.TOTP.Issuer, totpKey)
+	}
+	if err != nil {
+		// Users who already turned it on could not get past the second factor
+		enrolled, countErr := queries.CountTOTPUsers(context.Background())
+		if countErr != nil {
+			log.Fatalf("Failed to count authenticator app users: %v", countErr)
+		}
+		if enrolled > 0 {
+			log.Fatalf("TOTP key unavailable but %d users have authenticator app 2FA enabled: %v", enrolled, err)
+		}
+		log.Printf("TOTP two-factor authentication disabled: %v", err)
+	}
+
+	passkeys, err := webauthn.New(&webauthn.Config{
+		RPID:          dbConfig.WebAuthn.RPID,
+		RPDisplayName: dbConfig.WebAuthn.RPDisplayName,
+		RPOrigins:     dbConfig.WebAuthn.RPOrigins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: dbConfig.WebAuthn.ChallengeTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: dbConfig.WebAuthn.ChallengeTTL},
+		},
 	})
+	if err != nil {
+		log.Fatalf("Failed to configure WebAuthn: %v", err)
+	}
 
-	server := api.NewServer(queries, jwtMaker, emailService)
+	server := api.NewServer(dbConfig, database, queries, jwtMaker, emailService, smsService, passwordPolicy, emailPolicy, usernamePolicy, passwordConfig, avatars, files, totp, passkeys)
 
 	srv := &http.Server{
 		Addr:    ":8080",
 		Handler: server.Router(),
 	}
 
+	// Purge accounts whose deletion grace period has passed and data exports
+	// whose download link has expired
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	purger := jobs.NewAccountPurger(database, queries, files, dbConfig.AccountDeletion.GracePeriod)
+	go purger.Run(jobsCtx, dbConfig.AccountDeletion.PurgeInterval)
+	go jobs.NewExportCleaner(queries).Run(jobsCtx, dbConfig.DataExport.CleanupInterval)
+
 	go func() {
 		log.Printf("Starting server on %s", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -83,6 +219,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// deleteAccount schedules the caller's account for deletion. The account is
+// signed out everywhere and cannot log in, but can be restored until the
+// grace period ends and the purge job removes it.
+func (s *Server) deleteAccount(w http.ResponseWriter, r *http.Request) {
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if err := s.db.RequestAccountDeletion(r.Context(), db.RequestAccountDeletionParams{
+		ID:                       user.ID,
+		DeletionRestoreTokenHash: sql.NullString{String: hash, Valid: true},
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if err := s.db.RevokeUserSessions(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to revoke sessions: %v", err)
+	}
+	s.audit(r, user.ID, AuditAccountDeletionRequested, nil)
+
+	go func() {
+		if err := s.emailService.SendAccountDeletionScheduled(user.Email, token, s.deletionConfig.GracePeriod); err != nil {
+			log.Printf("Failed to send account deletion email: %v", err)
+		}
+	}()
+
+	s.clearTokenCookie(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Account scheduled for deletion",
+		"purge_after": time.Now().Add(s.deletionConfig.GracePeriod),
+	})
+}
+
+// restoreAccount handles the restore link from the deletion email.
+func (s *Server) restoreAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.db.RestoreAccountByToken(r.Context(), db.RestoreAccountByTokenParams{
+		DeletionRestoreTokenHash: sql.NullString{String: service.HashToken(r.URL.Query().Get("token")), Valid: true},
+		DeletionRequestedAt:      sql.NullTime{Time: time.Now().Add(-s.deletionConfig.GracePeriod), Valid: true},
+	})
+	if err != nil {
+		http.Redirect(w, r, "/login?restore_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	s.audit(r, userID, AuditAccountRestored, nil)
+	http.Redirect(w, r, "/login?restored=true", http.StatusSeeOther)
+}
+
+// adminRestoreAccount cancels a pending deletion on the user's behalf.
+func (s *Server) adminRestoreAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := s.db.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !user.DeletionRequestedAt.Valid {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Account is not pending deletion")
+		return
+	}
+
+	if err := s.db.RestoreAccount(r.Context(), user.ID); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	s.audit(r, user.ID, AuditAccountRestored, map[string]interface{}{"by": "admin"})
+	w.WriteHeader(http.StatusNoContent)
+}
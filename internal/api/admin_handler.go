@@ -0,0 +1,88 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+)
+
+const RoleAdmin = "admin"
+
+// requireAdmin looks the caller up on every request rather than trusting a
+// claim, so revoking the role takes effect immediately.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return s.auth.RequireAPIAuth(func(w http.ResponseWriter, r *http.Request) {
+		user, err := s.currentUser(r)
+		if err != nil {
+			s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+			return
+		}
+		if user.Role != RoleAdmin {
+			s.writeError(w, r, http.StatusForbidden, problem.CodeForbidden, "Forbidden")
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+func (s *Server) getUserPasswordPolicy(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := s.db.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":                      user.ID,
+		"username":                user.Username,
+		"password_policy_version": user.PasswordPolicyVersion,
+		"current_policy_version":  s.passwordPolicy.Version(),
+		"compliant":               int(user.PasswordPolicyVersion) >= s.passwordPolicy.Version(),
+	})
+}
+
+// requirePasswordChange flags a user so their next login only gets a
+// restricted token, e.g. after an admin reset or a leaked credential.
+func (s *Server) requirePasswordChange(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user ID")
+		return
+	}
+
+	if _, err := s.db.GetUserByID(r.Context(), userID); errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return
+	} else if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if err := s.db.SetMustChangePassword(r.Context(), db.SetMustChangePasswordParams{
+		ID:                 userID,
+		MustChangePassword: true,
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/middleware"
+	"github.com/yeboahd24/authentication/internal/problem"
+)
+
+// Account statuses. Only active accounts can sign in.
+const (
+	UserStatusPendingApproval = "pending_approval"
+	UserStatusActive          = "active"
+	UserStatusRejected        = "rejected"
+	UserStatusSuspended       = "suspended"
+)
+
+// handleAdminApprovals serves the approval queue page. Everything on it goes
+// through the admin API, which does its own checks, but non-admins are
+// turned away here too.
+func (s *Server) handleAdminApprovals(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil || user.Role != RoleAdmin {
+		s.writeError(w, r, http.StatusForbidden, problem.CodeForbidden, "Forbidden")
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":   "Approvals",
+		"Content": "admin_approvals", // This tells the layout which template to use
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
+		return
+	}
+}
+
+type PendingUserResponse struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	Username      string    `json:"username"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// listPendingUsers returns the accounts waiting for approval, oldest first.
+func (s *Server) listPendingUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.db.ListUsersByStatus(r.Context(), UserStatusPendingApproval)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	response := make([]PendingUserResponse, 0, len(users))
+	for _, user := range users {
+		response = append(response, PendingUserResponse{
+			ID:            user.ID.String(),
+			Email:         user.Email,
+			Username:      user.Username,
+			EmailVerified: user.EmailVerified,
+			CreatedAt:     user.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// changeUserStatus moves the user named in the URL from one status to
+// another and writes the error response if it cannot. The update only
+// applies if the user is still in the from status, so two admins acting on
+// the same account at once cannot both succeed.
+func (s *Server) changeUserStatus(w http.ResponseWriter, r *http.Request, from, to string) (db.User, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user ID")
+		return db.User{}, false
+	}
+
+	user, err := s.db.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return db.User{}, false
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return db.User{}, false
+	}
+
+	updated, err := s.db.UpdateUserStatus(r.Context(), db.UpdateUserStatusParams{
+		ID:         user.ID,
+		Status:     to,
+		FromStatus: from,
+	})
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return db.User{}, false
+	}
+	if updated == 0 {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Account is not "+statusDescription(from))
+		return db.User{}, false
+	}
+
+	user.Status = to
+	return user, true
+}
+
+func statusDescription(status string) string {
+	switch status {
+	case UserStatusPendingApproval:
+		return "awaiting approval"
+	case UserStatusSuspended:
+		return "suspended"
+	case UserStatusRejected:
+		return "rejected"
+	default:
+		return "active"
+	}
+}
+
+// approveUser lets a pending account sign in and tells its owner.
+func (s *Server) approveUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.changeUserStatus(w, r, UserStatusPendingApproval, UserStatusActive)
+	if !ok {
+		return
+	}
+	s.audit(r, user.ID, AuditAccountApproved, map[string]interface{}{"by": adminID(r)})
+
+	go func() {
+		if err := s.emailService.SendAccountApproved(user.Email, user.Username); err != nil {
+			log.Printf("Failed to send account approval email: %v", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type RejectUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// rejectUser turns a pending account down. The reason, if given, is passed
+// on to the user in the email.
+func (s *Server) rejectUser(w http.ResponseWriter, r *http.Request) {
+	var req RejectUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, ok := s.changeUserStatus(w, r, UserStatusPendingApproval, UserStatusRejected)
+	if !ok {
+		return
+	}
+	s.audit(r, user.ID, AuditAccountRejected, map[string]interface{}{
+		"by":     adminID(r),
+		"reason": req.Reason,
+	})
+
+	go func() {
+		if err := s.emailService.SendAccountRejected(user.Email, user.Username, req.Reason); err != nil {
+			log.Printf("Failed to send account rejection email: %v", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// suspendUser blocks an active account from signing in and ends its
+// sessions.
+func (s *Server) suspendUser(w http.ResponseWriter, r *http.Request) {
+	if chi.URLParam(r, "id") == adminID(r) {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "You cannot suspend your own account")
+		return
+	}
+
+	user, ok := s.changeUserStatus(w, r, UserStatusActive, UserStatusSuspended)
+	if !ok {
+		return
+	}
+	if err := s.db.RevokeUserSessions(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to revoke sessions of suspended user: %v", err)
+	}
+	s.audit(r, user.ID, AuditAccountSuspended, map[string]interface{}{"by": adminID(r)})
+
+	go func() {
+		if err := s.emailService.SendAccountSuspended(user.Email, user.Username); err != nil {
+			log.Printf("Failed to send account suspension email: %v", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reactivateUser lifts a suspension.
+func (s *Server) reactivateUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.changeUserStatus(w, r, UserStatusSuspended, UserStatusActive)
+	if !ok {
+		return
+	}
+	s.audit(r, user.ID, AuditAccountReactivated, map[string]interface{}{"by": adminID(r)})
+
+	go func() {
+		if err := s.emailService.SendAccountReactivated(user.Email, user.Username); err != nil {
+			log.Printf("Failed to send account reactivation email: %v", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminID returns the ID of the admin making the request, for audit events
+// recorded against another user.
+func adminID(r *http.Request) string {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		return ""
+	}
+	return claims.UserID
+}
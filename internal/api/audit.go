@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+)
+
+// Audit event types
+const (
+	AuditPasswordChanged          = "password_changed"
+	AuditPasswordReset            = "password_reset"
+	AuditEmailChangeRequested     = "email_change_requested"
+	AuditEmailChanged             = "email_changed"
+	AuditEmailChangeReverted      = "email_change_reverted"
+	AuditAccountLocked            = "account_locked"
+	AuditAccountDeletionRequested = "account_deletion_requested"
+	AuditAccountRestored          = "account_restored"
+	AuditDataExportRequested      = "data_export_requested"
+	AuditDataExported             = "data_exported"
+	AuditUsernameChanged          = "username_changed"
+	AuditMagicLinkLogin           = "magic_link_login"
+	AuditEmailCodeLogin           = "email_code_login"
+	AuditMFACompleted             = "mfa_completed"
+	AuditMFAEnabled               = "mfa_enabled"
+	AuditMFADisabled              = "mfa_disabled"
+	AuditRecoveryCodesRegenerated = "recovery_codes_regenerated"
+	AuditPasskeyAdded             = "passkey_added"
+	AuditPasskeyRemoved           = "passkey_removed"
+	AuditPasskeyLogin             = "passkey_login"
+	AuditPhoneAdded               = "phone_added"
+	AuditPhoneVerified            = "phone_verified"
+	AuditPhoneRemoved             = "phone_removed"
+	AuditReauthenticated          = "reauthenticated"
+	AuditEmailAdded               = "email_added"
+	AuditEmailVerified            = "email_verified"
+	AuditEmailRemoved             = "email_removed"
+	AuditInviteCreated            = "invite_created"
+	AuditInviteRevoked            = "invite_revoked"
+	AuditInviteRedeemed           = "invite_redeemed"
+	AuditAccountApproved          = "account_approved"
+	AuditAccountRejected          = "account_rejected"
+	AuditAccountSuspended         = "account_suspended"
+	AuditAccountReactivated       = "account_reactivated"
+)
+
+// audit records a security-relevant event for userID. Failures are logged
+// rather than returned so auditing never breaks the action being audited.
+func (s *Server) audit(r *http.Request, userID uuid.UUID, eventType string, metadata map[string]interface{}) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		log.Printf("Failed to encode audit metadata: %v", err)
+		encoded = []byte("{}")
+	}
+
+	if err := s.db.CreateAuditEvent(r.Context(), db.CreateAuditEventParams{
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		EventType: eventType,
+		IpAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+		Metadata:  encoded,
+	}); err != nil {
+		log.Printf("Failed to record audit event %s: %v", eventType, err)
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/yeboahd24/authentication/internal/db/dberr"
+	"github.com/yeboahd24/authentication/internal/problem"
+)
+
+// writeDBError answers a request whose write failed. Writes that ran into an
+// existing record or a concurrent request get a 409 saying what clashed;
+// anything else is logged and answered with a 500.
+func (s *Server) writeDBError(w http.ResponseWriter, r *http.Request, err error) {
+	err = dberr.Translate(err)
+	switch {
+	case errors.Is(err, dberr.ErrEmailTaken):
+		s.writeError(w, r, http.StatusConflict, problem.CodeEmailTaken, "Email already registered")
+	case errors.Is(err, dberr.ErrUsernameTaken):
+		s.writeError(w, r, http.StatusConflict, problem.CodeUsernameTaken, "Username already taken")
+	case errors.Is(err, dberr.ErrUsernameConfusable):
+		s.writeError(w, r, http.StatusConflict, problem.CodeUsernameConfusable, usernameConfusableMessage)
+	case errors.Is(err, dberr.ErrConflict), errors.Is(err, dberr.ErrReferenceNotFound):
+		s.writeError(w, r, http.StatusConflict, problem.CodeConflict, "The request conflicts with the current state of the resource")
+	case errors.Is(err, dberr.ErrConcurrentUpdate):
+		s.writeError(w, r, http.StatusConflict, problem.CodeConcurrentUpdate, "The request conflicted with another one; please try again")
+	default:
+		log.Printf("Database error: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+	}
+}
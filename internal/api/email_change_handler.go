@@ -0,0 +1,202 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+	Password string `json:"password"`
+}
+
+// requestEmailChange starts an email change. Nothing changes on the account
+// until the link sent to the new address is followed.
+func (s *Server) requestEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	if req.NewEmail == "" || req.Password == "" {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "New email and password are required")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	if req.NewEmail == user.Email {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "That is already your email address")
+		return
+	}
+	if violations := s.emailPolicy.Evaluate(r.Context(), req.NewEmail); len(violations) > 0 {
+		s.writeViolations(w, r, "new_email", "Email address is not allowed", violations)
+		return
+	}
+	exists, err := s.db.CheckEmailExists(r.Context(), req.NewEmail)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if exists {
+		s.writeError(w, r, http.StatusConflict, problem.CodeEmailTaken, "Email already registered")
+		return
+	}
+
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	// Only the latest request can be confirmed
+	if err := s.db.CancelPendingEmailChanges(r.Context(), user.ID); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if _, err := s.db.CreateEmailChangeRequest(r.Context(), db.CreateEmailChangeRequestParams{
+		UserID:           user.ID,
+		OldEmail:         user.Email,
+		OldEmailVerified: user.EmailVerified,
+		NewEmail:         req.NewEmail,
+		ConfirmTokenHash: hash,
+		ConfirmExpiresAt: time.Now().Add(s.emailChangeConfig.ConfirmTTL),
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	s.audit(r, user.ID, AuditEmailChangeRequested, map[string]interface{}{"new_email": req.NewEmail})
+
+	go func() {
+		if err := s.emailService.SendEmailChangeConfirmation(req.NewEmail, token, s.emailChangeConfig.ConfirmTTL); err != nil {
+			log.Printf("Failed to send email change confirmation: %v", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// confirmEmailChange handles the link sent to the new address. Following it
+// proves ownership, so the new address counts as verified. The old address
+// is told about the change and given a link to undo it.
+func (s *Server) confirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	change, err := s.db.ConfirmEmailChange(r.Context(), service.HashToken(r.URL.Query().Get("token")))
+	if err != nil {
+		http.Redirect(w, r, "/login?email_change_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	// The address may have been taken since the request was made
+	exists, err := s.db.CheckEmailExists(r.Context(), change.NewEmail)
+	if err != nil || exists {
+		http.Redirect(w, r, "/login?email_change_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	if err := s.db.UpdateUserEmail(r.Context(), db.UpdateUserEmailParams{
+		ID:            change.UserID,
+		Email:         change.NewEmail,
+		EmailVerified: true,
+	}); err != nil {
+		log.Printf("Failed to update email: %v", err)
+		http.Redirect(w, r, "/login?email_change_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	revertToken, revertHash, err := service.GenerateToken()
+	if err != nil {
+		log.Printf("Failed to generate revert token: %v", err)
+	} else if err := s.db.SetEmailChangeRevertToken(r.Context(), db.SetEmailChangeRevertTokenParams{
+		ID:              change.ID,
+		RevertTokenHash: sql.NullString{String: revertHash, Valid: true},
+		RevertExpiresAt: sql.NullTime{Time: time.Now().Add(s.emailChangeConfig.RevertTTL), Valid: true},
+	}); err != nil {
+		log.Printf("Failed to store revert token: %v", err)
+	} else {
+		go func() {
+			if err := s.emailService.SendEmailChangedNotification(change.OldEmail, change.NewEmail, revertToken, s.emailChangeConfig.RevertTTL); err != nil {
+				log.Printf("Failed to send email changed notification: %v", err)
+			}
+		}()
+	}
+
+	s.audit(r, change.UserID, AuditEmailChanged, map[string]interface{}{
+		"old_email": change.OldEmail,
+		"new_email": change.NewEmail,
+	})
+
+	http.Redirect(w, r, "/login?email_changed=true", http.StatusSeeOther)
+}
+
+// revertEmailChange handles the "this wasn't me" link sent to the old
+// address. It restores the old address, locks the account and signs it out
+// everywhere; the owner unlocks it by resetting the password.
+func (s *Server) revertEmailChange(w http.ResponseWriter, r *http.Request) {
+	revertHash := sql.NullString{String: service.HashToken(r.URL.Query().Get("token")), Valid: true}
+	change, err := s.db.RevertEmailChange(r.Context(), revertHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Redirect(w, r, "/login?email_change_failed=true", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to revert email change: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
+		return
+	}
+
+	if err := s.db.UpdateUserEmail(r.Context(), db.UpdateUserEmailParams{
+		ID:            change.UserID,
+		Email:         change.OldEmail,
+		EmailVerified: change.OldEmailVerified,
+	}); err != nil {
+		log.Printf("Failed to restore email: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
+		return
+	}
+
+	if err := s.db.LockUser(r.Context(), change.UserID); err != nil {
+		log.Printf("Failed to lock account: %v", err)
+	}
+	if err := s.db.RevokeUserSessions(r.Context(), change.UserID); err != nil {
+		log.Printf("Failed to revoke sessions: %v", err)
+	}
+	// Links and codes already mailed to the address that took over the
+	// account would let its holder sign in or reset the password, which
+	// unlocks the account again
+	if err := s.db.MarkPasswordResetTokensUsed(r.Context(), change.UserID); err != nil {
+		log.Printf("Failed to invalidate reset tokens: %v", err)
+	}
+	if err := s.db.InvalidateMagicLinkTokens(r.Context(), change.UserID); err != nil {
+		log.Printf("Failed to invalidate sign-in links: %v", err)
+	}
+	if err := s.db.InvalidateUserOTPs(r.Context(), change.UserID); err != nil {
+		log.Printf("Failed to invalidate one-time codes: %v", err)
+	}
+
+	s.audit(r, change.UserID, AuditEmailChangeReverted, map[string]interface{}{
+		"restored_email": change.OldEmail,
+		"removed_email":  change.NewEmail,
+	})
+	s.audit(r, change.UserID, AuditAccountLocked, map[string]interface{}{"reason": "email_change_reverted"})
+
+	http.Redirect(w, r, "/login?email_reverted=true", http.StatusSeeOther)
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// One-time code purposes. A code only works for the purpose it was issued
+// for.
+const (
+	otpPurposeLogin       = "login"
+	otpPurposeMFA         = "mfa"
+	otpPurposePhoneVerify = "phone_verify"
+	otpPurposePhoneMFA    = "phone_mfa"
+)
+
+// otpDigits is the length of emailed and texted one-time codes.
+const otpDigits = 6
+
+// emailOTPPurposes are the codes that are delivered by email.
+var emailOTPPurposes = []string{otpPurposeLogin, otpPurposeMFA}
+
+// sendAllowed reports whether another message may go out when count tells
+// how many were sent since a given time: the last one has to be at least
+// interval old and fewer than dailyLimit can have gone out in 24 hours.
+func sendAllowed(count func(since time.Time) (int64, error), interval time.Duration, dailyLimit int) (bool, error) {
+	recent, err := count(time.Now().Add(-interval))
+	if err != nil || recent > 0 {
+		return false, err
+	}
+
+	today, err := count(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		return false, err
+	}
+	return today < int64(dailyLimit), nil
+}
+
+// codeAllowed reports whether another code for one of purposes may be sent
+// to user. A new code also resets the guesses allowed, so without a limit a
+// code could be guessed at forever by asking for fresh ones.
+func (s *Server) codeAllowed(r *http.Request, user db.User, purposes []string, interval time.Duration, dailyLimit int) (bool, error) {
+	return sendAllowed(func(since time.Time) (int64, error) {
+		return s.db.CountOTPsSince(r.Context(), db.CountOTPsSinceParams{
+			UserID:   user.ID,
+			Purposes: purposes,
+			Since:    since,
+		})
+	}, interval, dailyLimit)
+}
+
+// emailCodeAllowed reports whether another code may be emailed to user.
+// Every caller of sendEmailOTP checks it first.
+func (s *Server) emailCodeAllowed(r *http.Request, user db.User) (bool, error) {
+	return s.codeAllowed(r, user, emailOTPPurposes, s.emailOTPConfig.ResendInterval, s.emailOTPConfig.DailyLimit)
+}
+
+// issueOTP stores a new code for user and purpose, replacing any code issued
+// for it earlier, and returns it for delivery.
+func (s *Server) issueOTP(r *http.Request, user db.User, purpose string, ttl time.Duration) (string, error) {
+	code, err := service.GenerateCode(otpDigits)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.db.InvalidateOTPs(r.Context(), db.InvalidateOTPsParams{
+		UserID:  user.ID,
+		Purpose: purpose,
+	}); err != nil {
+		return "", err
+	}
+	if err := s.db.CreateOTP(r.Context(), db.CreateOTPParams{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		CodeHash:  service.HashCode(user.ID.String(), code),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// checkOTP reports whether code is the user's current code for purpose and
+// uses it up if so. Every guess counts against the code, and once
+// maxAttempts run out it stops working even with the right digits.
+func (s *Server) checkOTP(r *http.Request, user db.User, purpose, code string, maxAttempts int) (bool, error) {
+	otp, err := s.db.GetActiveOTP(r.Context(), db.GetActiveOTPParams{
+		UserID:  user.ID,
+		Purpose: purpose,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	attempts, err := s.db.IncrementOTPAttempts(r.Context(), otp.ID)
+	if err != nil {
+		return false, err
+	}
+	if int(attempts) > maxAttempts {
+		return false, nil
+	}
+
+	hash := service.HashCode(user.ID.String(), code)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(otp.CodeHash)) != 1 {
+		return false, nil
+	}
+
+	// The conditional update makes sure a code is only accepted once
+	used, err := s.db.UseOTP(r.Context(), otp.ID)
+	if err != nil {
+		return false, err
+	}
+	return used == 1, nil
+}
+
+// sendEmailOTP emails user a new code for purpose.
+func (s *Server) sendEmailOTP(r *http.Request, user db.User, purpose string) error {
+	code, err := s.issueOTP(r, user, purpose, s.emailOTPConfig.CodeTTL)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.emailService.SendLoginCode(user.Email, code, s.emailOTPConfig.CodeTTL); err != nil {
+			log.Printf("Failed to send login code email: %v", err)
+		}
+	}()
+	return nil
+}
+
+// checkEmailOTP checks an emailed code for purpose.
+func (s *Server) checkEmailOTP(r *http.Request, user db.User, purpose, code string) (bool, error) {
+	return s.checkOTP(r, user, purpose, code, s.emailOTPConfig.MaxAttempts)
+}
+
+// requestLoginCode emails a one-time sign-in code. It always answers 202 so
+// it cannot be used to enumerate users.
+func (s *Server) requestLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	// Requests over the limit are dropped silently, like those for unknown
+	// addresses
+	user, err := s.findUserByIdentifier(r.Context(), req.Email)
+	if err == nil {
+		allowed, err := s.emailCodeAllowed(r, user)
+		if err != nil {
+			log.Printf("Failed to check email code limit: %v", err)
+		} else if allowed {
+			if err := s.sendEmailOTP(r, user, otpPurposeLogin); err != nil {
+				log.Printf("Failed to send login code: %v", err)
+			}
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Failed to look up user for login code: %v", err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type VerifyLoginCodeRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// verifyLoginCode signs in with an emailed code instead of a password.
+func (s *Server) verifyLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req VerifyLoginCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.findUserByIdentifier(r.Context(), req.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCode, "Invalid or expired code")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	valid, err := s.checkEmailOTP(r, user, otpPurposeLogin, req.Code)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCode, "Invalid or expired code")
+		return
+	}
+
+	// Receiving the code proves control of the mailbox
+	if !user.EmailVerified {
+		if err := s.db.MarkEmailVerified(r.Context(), user.ID); err != nil {
+			log.Printf("Failed to mark email verified: %v", err)
+		} else {
+			user.EmailVerified = true
+		}
+	}
+
+	if code, reason := s.loginBlockedReason(user); code != "" {
+		s.writeError(w, r, http.StatusForbidden, code, reason)
+		return
+	}
+
+	s.audit(r, user.ID, AuditEmailCodeLogin, nil)
+	if s.mfaRequired(user) {
+		s.startMFA(w, r, user, MFAMethodEmail)
+		return
+	}
+	s.completeLogin(w, r, user)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// writeError answers a failed request. code is one of the problem codes and
+// detail tells the user what went wrong this time. API clients get
+// application/problem+json; browsers navigating to a page get an HTML error
+// page instead.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	s.writeProblem(w, r, problem.New(r, status, code, detail))
+}
+
+func (s *Server) writeProblem(w http.ResponseWriter, r *http.Request, details *problem.Details) {
+	if !prefersHTML(r) {
+		problem.Write(w, details)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":   details.Title,
+		"Content": "error",
+		"Problem": details,
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(details.Status)
+	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// writePolicyViolations rejects a password with the list of rules it broke so
+// forms can show them next to the field.
+func (s *Server) writePolicyViolations(w http.ResponseWriter, r *http.Request, violations []service.PolicyViolation) {
+	s.writeViolations(w, r, "password", "Password does not meet the password policy", violations)
+}
+
+// writeViolations rejects the value of a form field with the policy rules it
+// broke, as the field and violations members of the problem.
+func (s *Server) writeViolations(w http.ResponseWriter, r *http.Request, field, message string, violations []service.PolicyViolation) {
+	s.writeProblem(w, r, problem.New(r, http.StatusBadRequest, problem.CodeValidationFailed, message).
+		With("field", field).
+		With("violations", violations))
+}
+
+// handleProblemType documents a problem type; problem details link here
+// from their type member.
+func (s *Server) handleProblemType(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !problem.Known(code) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Unknown problem type")
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":   problem.Title(code, 0),
+		"Content": "error",
+		"Problem": &problem.Details{Title: problem.Title(code, 0), Code: code},
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+	}
+}
+
+// prefersHTML reports whether the Accept header of r ranks text/html above
+// JSON, as browsers do when following a link. fetch sends */* by default and
+// so gets JSON.
+func prefersHTML(r *http.Request) bool {
+	var htmlQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			htmlQ = max(htmlQ, q)
+		case "application/json", problem.ContentType, "application/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return htmlQ > jsonQ
+}
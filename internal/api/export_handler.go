@@ -0,0 +1,395 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// DataExport is the archive handed to a user who asks for their data.
+// Password and token hashes are never included; for tokens only their
+// lifecycle is exported. We do not record consents yet, so there is nothing
+// to export for them.
+type DataExport struct {
+	GeneratedAt         time.Time                           `json:"generated_at"`
+	Profile             ExportProfile                       `json:"profile"`
+	MFA                 ExportMFA                           `json:"mfa"`
+	EmailAddresses      []ExportEmailAddress                `json:"email_addresses"`
+	Passkeys            []PasskeyResponse                   `json:"passkeys"`
+	Sessions            []ExportSession                     `json:"sessions"`
+	AuditEvents         []ExportAuditEvent                  `json:"audit_events"`
+	PasswordResetTokens []db.ListUserPasswordResetTokensRow `json:"password_reset_tokens"`
+	EmailChanges        []db.ListUserEmailChangeRequestsRow `json:"email_change_requests"`
+	UsernameHolds       []db.ListUserUsernameHoldsRow       `json:"username_holds"`
+	Invites             []InviteResponse                    `json:"invites"`
+}
+
+type ExportProfile struct {
+	ID                  uuid.UUID  `json:"id"`
+	Email               string     `json:"email"`
+	Username            string     `json:"username"`
+	Role                string     `json:"role"`
+	Status              string     `json:"status"`
+	EmailVerified       bool       `json:"email_verified"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	PasswordChangedAt   time.Time  `json:"password_changed_at"`
+	LockedAt            *time.Time `json:"locked_at,omitempty"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
+	UsernameChangedAt   *time.Time `json:"username_changed_at,omitempty"`
+	PhoneNumber         string     `json:"phone_number,omitempty"`
+	PhoneVerifiedAt     *time.Time `json:"phone_verified_at,omitempty"`
+	DisplayName         string     `json:"display_name"`
+	Bio                 string     `json:"bio"`
+	Locale              string     `json:"locale"`
+	Timezone            string     `json:"timezone"`
+}
+
+// ExportEmailAddress is an additional address the user can sign in with.
+type ExportEmailAddress struct {
+	Email      string     `json:"email"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ExportMFA describes the second factors the user has set up. Secrets are
+// left out.
+type ExportMFA struct {
+	EmailCodes             bool       `json:"email_codes"`
+	PhoneCodes             bool       `json:"phone_codes"`
+	TOTPEnabledAt          *time.Time `json:"totp_enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+type ExportSession struct {
+	ID        uuid.UUID  `json:"id"`
+	UserAgent string     `json:"user_agent"`
+	IPAddress string     `json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type ExportAuditEvent struct {
+	EventType string          `json:"event_type"`
+	IPAddress string          `json:"ip_address"`
+	UserAgent string          `json:"user_agent"`
+	Metadata  json.RawMessage `json:"metadata"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// exportMyData returns the caller's data export. Small exports are served
+// directly; larger ones are built in the background and the download link
+// is emailed once ready.
+func (s *Server) exportMyData(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	records, err := s.db.CountUserExportRecords(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if records <= int64(s.exportConfig.AsyncThreshold) {
+		export, err := s.buildDataExport(r.Context(), user)
+		if err != nil {
+			log.Printf("Failed to build data export: %v", err)
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+			return
+		}
+
+		s.audit(r, user.ID, AuditDataExported, nil)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename(export.GeneratedAt)))
+		json.NewEncoder(w).Encode(export)
+		return
+	}
+
+	job, err := s.db.CreateDataExport(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditDataExportRequested, map[string]interface{}{"export_id": job.ID})
+
+	go s.produceDataExport(user, job.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     job.ID,
+		"status": job.Status,
+	})
+}
+
+// getDataExport reports the status of one of the caller's background
+// exports.
+func (s *Server) getDataExport(w http.ResponseWriter, r *http.Request) {
+	exportID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid export ID")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	job, err := s.db.GetDataExport(r.Context(), db.GetDataExportParams{ID: exportID, UserID: user.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Export not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":           job.ID,
+		"status":       job.Status,
+		"created_at":   job.CreatedAt,
+		"completed_at": nullTimePtr(job.CompletedAt),
+		"expires_at":   nullTimePtr(job.ExpiresAt),
+	})
+}
+
+// downloadDataExport serves a finished export from the emailed link. The
+// link only works for the account it was issued to, so a leaked link is
+// useless without the owner's session.
+func (s *Server) downloadDataExport(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	job, err := s.db.GetDataExportByToken(r.Context(), db.GetDataExportByTokenParams{
+		DownloadTokenHash: sql.NullString{String: service.HashToken(r.URL.Query().Get("token")), Valid: true},
+		UserID:            user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeInvalidToken, "Download link is invalid or has expired")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	file, err := os.Open(job.FilePath.String)
+	if err != nil {
+		log.Printf("Failed to open data export %s: %v", job.ID, err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	defer file.Close()
+
+	s.audit(r, user.ID, AuditDataExported, map[string]interface{}{"export_id": job.ID})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename(job.CreatedAt)))
+	http.ServeContent(w, r, "", job.CompletedAt.Time, file)
+}
+
+// produceDataExport builds an export in the background, writes it to the
+// export directory and emails the owner a download link.
+func (s *Server) produceDataExport(user db.User, exportID uuid.UUID) {
+	ctx := context.Background()
+	if err := s.writeDataExport(ctx, user, exportID); err != nil {
+		log.Printf("Failed to produce data export %s: %v", exportID, err)
+		if err := s.db.FailDataExport(ctx, exportID); err != nil {
+			log.Printf("Failed to mark data export %s as failed: %v", exportID, err)
+		}
+	}
+}
+
+func (s *Server) writeDataExport(ctx context.Context, user db.User, exportID uuid.UUID) error {
+	export, err := s.buildDataExport(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.exportConfig.Dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(s.exportConfig.Dir, exportID.String()+".json")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(export); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if err := s.db.CompleteDataExport(ctx, db.CompleteDataExportParams{
+		ID:                exportID,
+		FilePath:          sql.NullString{String: path, Valid: true},
+		DownloadTokenHash: sql.NullString{String: hash, Valid: true},
+		ExpiresAt:         sql.NullTime{Time: time.Now().Add(s.exportConfig.LinkTTL), Valid: true},
+	}); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if err := s.emailService.SendDataExportReady(user.Email, token, s.exportConfig.LinkTTL); err != nil {
+		log.Printf("Failed to send data export email: %v", err)
+	}
+	return nil
+}
+
+// buildDataExport gathers everything held about user.
+func (s *Server) buildDataExport(ctx context.Context, user db.User) (DataExport, error) {
+	export := DataExport{
+		GeneratedAt: time.Now().UTC(),
+		Profile: ExportProfile{
+			ID:                  user.ID,
+			Email:               user.Email,
+			Username:            user.Username,
+			Role:                user.Role,
+			Status:              user.Status,
+			EmailVerified:       user.EmailVerified,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
+			PasswordChangedAt:   user.PasswordChangedAt,
+			LockedAt:            nullTimePtr(user.LockedAt),
+			DeletionRequestedAt: nullTimePtr(user.DeletionRequestedAt),
+			UsernameChangedAt:   nullTimePtr(user.UsernameChangedAt),
+			PhoneNumber:         user.PhoneNumber.String,
+			PhoneVerifiedAt:     nullTimePtr(user.PhoneVerifiedAt),
+		},
+		MFA: ExportMFA{
+			EmailCodes:    user.EmailOtpMfaEnabled,
+			PhoneCodes:    user.PhoneMfaEnabled,
+			TOTPEnabledAt: nullTimePtr(user.TotpEnabledAt),
+		},
+		EmailAddresses: []ExportEmailAddress{},
+		Passkeys:       []PasskeyResponse{},
+		Sessions:       []ExportSession{},
+		AuditEvents:    []ExportAuditEvent{},
+		Invites:        []InviteResponse{},
+	}
+
+	profile, err := s.loadProfile(ctx, user.ID)
+	if err != nil {
+		return export, fmt.Errorf("failed to load profile: %w", err)
+	}
+	export.Profile.DisplayName = profile.DisplayName
+	export.Profile.Bio = profile.Bio
+	export.Profile.Locale = profile.Locale
+	export.Profile.Timezone = profile.Timezone
+
+	identifiers, err := s.db.ListUserIdentifiers(ctx, user.ID)
+	if err != nil {
+		return export, fmt.Errorf("failed to list email addresses: %w", err)
+	}
+	for _, identifier := range identifiers {
+		export.EmailAddresses = append(export.EmailAddresses, ExportEmailAddress{
+			Email:      identifier.Email,
+			VerifiedAt: nullTimePtr(identifier.VerifiedAt),
+			CreatedAt:  identifier.CreatedAt,
+		})
+	}
+
+	passkeys, err := s.db.ListUserWebAuthnCredentials(ctx, user.ID)
+	if err != nil {
+		return export, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	for _, passkey := range passkeys {
+		export.Passkeys = append(export.Passkeys, newPasskeyResponse(passkey))
+	}
+
+	sessions, err := s.db.ListUserSessions(ctx, user.ID)
+	if err != nil {
+		return export, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, ExportSession{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			IPAddress: session.IpAddress,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			RevokedAt: nullTimePtr(session.RevokedAt),
+		})
+	}
+
+	events, err := s.db.ListUserAuditEvents(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return export, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	for _, event := range events {
+		export.AuditEvents = append(export.AuditEvents, ExportAuditEvent{
+			EventType: event.EventType,
+			IPAddress: event.IpAddress,
+			UserAgent: event.UserAgent,
+			Metadata:  event.Metadata,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	if export.MFA.RecoveryCodesRemaining, err = s.db.CountUnusedRecoveryCodes(ctx, user.ID); err != nil {
+		return export, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	if export.PasswordResetTokens, err = s.db.ListUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return export, fmt.Errorf("failed to list password reset tokens: %w", err)
+	}
+	if export.EmailChanges, err = s.db.ListUserEmailChangeRequests(ctx, user.ID); err != nil {
+		return export, fmt.Errorf("failed to list email change requests: %w", err)
+	}
+	if export.UsernameHolds, err = s.db.ListUserUsernameHolds(ctx, user.ID); err != nil {
+		return export, fmt.Errorf("failed to list username holds: %w", err)
+	}
+
+	invites, err := s.db.ListInvitesByInviter(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return export, fmt.Errorf("failed to list invites: %w", err)
+	}
+	for _, invite := range invites {
+		export.Invites = append(export.Invites, newInviteResponse(invite))
+	}
+
+	return export, nil
+}
+
+func exportFilename(at time.Time) string {
+	return "data-export-" + at.UTC().Format("20060102-150405") + ".json"
+}
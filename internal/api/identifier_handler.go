@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// findUserByIdentifier resolves what someone typed to sign in: a username,
+// the primary email address or one of the account's verified additional
+// addresses.
+func (s *Server) findUserByIdentifier(ctx context.Context, identifier string) (db.User, error) {
+	if !strings.Contains(identifier, "@") {
+		return s.db.GetUserByUsername(ctx, s.usernames.Normalize(identifier))
+	}
+
+	user, err := s.db.GetUserByEmail(ctx, identifier)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return user, err
+	}
+	userID, err := s.db.GetUserIDByVerifiedIdentifier(ctx, identifier)
+	if err != nil {
+		return db.User{}, err
+	}
+	return s.db.GetUserByID(ctx, userID)
+}
+
+type EmailAddressResponse struct {
+	ID       string     `json:"id,omitempty"`
+	Email    string     `json:"email"`
+	Primary  bool       `json:"primary"`
+	Verified bool       `json:"verified"`
+	AddedAt  *time.Time `json:"added_at,omitempty"`
+}
+
+// listEmails returns the caller's primary address followed by the
+// additional ones they can sign in with.
+func (s *Server) listEmails(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	identifiers, err := s.db.ListUserIdentifiers(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	emails := []EmailAddressResponse{{
+		Email:    user.Email,
+		Primary:  true,
+		Verified: user.EmailVerified,
+	}}
+	for _, identifier := range identifiers {
+		addedAt := identifier.CreatedAt
+		emails = append(emails, EmailAddressResponse{
+			ID:       identifier.ID.String(),
+			Email:    identifier.Email,
+			Verified: identifier.VerifiedAt.Valid,
+			AddedAt:  &addedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(emails)
+}
+
+type AddEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// addEmail adds an address the caller can sign in with once they follow
+// the confirmation link sent to it.
+func (s *Server) addEmail(w http.ResponseWriter, r *http.Request) {
+	var req AddEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	if violations := s.emailPolicy.Evaluate(r.Context(), req.Email); len(violations) > 0 {
+		s.writeViolations(w, r, "email", "Email address is not allowed", violations)
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	exists, err := s.db.CheckEmailExists(r.Context(), req.Email)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if exists || strings.EqualFold(req.Email, user.Email) {
+		s.writeError(w, r, http.StatusConflict, problem.CodeEmailTaken, "Email already registered")
+		return
+	}
+
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	ttl := s.verificationConfig.TokenTTL
+	if _, err := s.db.CreateUserIdentifier(r.Context(), db.CreateUserIdentifierParams{
+		UserID:                user.ID,
+		Email:                 req.Email,
+		VerificationTokenHash: sql.NullString{String: hash, Valid: true},
+		VerificationExpiresAt: sql.NullTime{Time: time.Now().Add(ttl), Valid: true},
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditEmailAdded, map[string]interface{}{"email": req.Email})
+
+	go func() {
+		if err := s.emailService.SendIdentifierVerification(req.Email, token, ttl); err != nil {
+			log.Printf("Failed to send email address confirmation: %v", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verifyIdentifier handles the link sent to an additional address and sends
+// the browser on to the login page with the outcome. It fails if another
+// account has claimed the address in the meantime.
+func (s *Server) verifyIdentifier(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Redirect(w, r, "/login?email_add_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	identifier, err := s.db.VerifyUserIdentifier(r.Context(), sql.NullString{String: service.HashToken(token), Valid: true})
+	if err != nil {
+		http.Redirect(w, r, "/login?email_add_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	s.audit(r, identifier.UserID, AuditEmailVerified, map[string]interface{}{"email": identifier.Email})
+	http.Redirect(w, r, "/login?email_added=true", http.StatusSeeOther)
+}
+
+// makeEmailPrimary swaps a verified additional address with the primary one.
+// The old primary address stays on the account as an additional address if
+// it was verified, and is dropped otherwise.
+func (s *Server) makeEmailPrimary(w http.ResponseWriter, r *http.Request) {
+	var req MFAPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Email address not found")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	identifier, err := s.db.GetUserIdentifier(r.Context(), db.GetUserIdentifierParams{ID: id, UserID: user.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Email address not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !identifier.VerifiedAt.Valid {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Confirm this email address first")
+		return
+	}
+
+	// The old primary address becomes an additional one, so the swap either
+	// happens completely or not at all
+	if err := s.inTx(r.Context(), func(queries *db.Queries) error {
+		if _, err := queries.DeleteUserIdentifier(r.Context(), db.DeleteUserIdentifierParams{ID: id, UserID: user.ID}); err != nil {
+			return err
+		}
+		if err := queries.UpdateUserEmail(r.Context(), db.UpdateUserEmailParams{
+			ID:            user.ID,
+			Email:         identifier.Email,
+			EmailVerified: true,
+		}); err != nil {
+			return err
+		}
+		// A pending change of the primary address would undo this one
+		if err := queries.CancelPendingEmailChanges(r.Context(), user.ID); err != nil {
+			return err
+		}
+		if !user.EmailVerified {
+			return nil
+		}
+		_, err := queries.CreateUserIdentifier(r.Context(), db.CreateUserIdentifierParams{
+			UserID:     user.ID,
+			Email:      user.Email,
+			VerifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		return err
+	}); err != nil {
+		s.writeDBError(w, r, err)
+		return
+	}
+
+	s.audit(r, user.ID, AuditEmailChanged, map[string]interface{}{
+		"old_email": user.Email,
+		"new_email": identifier.Email,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteEmail removes one of the caller's additional addresses. The primary
+// address can only be replaced, not removed.
+func (s *Server) deleteEmail(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Email address not found")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	identifier, err := s.db.GetUserIdentifier(r.Context(), db.GetUserIdentifierParams{ID: id, UserID: user.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Email address not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if _, err := s.db.DeleteUserIdentifier(r.Context(), db.DeleteUserIdentifierParams{ID: id, UserID: user.ID}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditEmailRemoved, map[string]interface{}{"email": identifier.Email})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/yeboahd24/authentication/internal/config"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// canInvite reports whether user may create invites. Admins always can.
+func (s *Server) canInvite(user db.User) bool {
+	if user.Role == RoleAdmin {
+		return true
+	}
+	for _, role := range s.registrationConfig.InviteRoles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInvite finds the usable invite for code and makes sure it may be used
+// to register email. It returns an error message suitable for the client.
+func (s *Server) checkInvite(r *http.Request, code, email string) (db.Invite, string, error) {
+	if code == "" {
+		return db.Invite{}, "An invite is required to register", nil
+	}
+
+	invite, err := s.db.GetUsableInvite(r.Context(), service.HashToken(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Invite{}, "This invite is invalid or has expired", nil
+	}
+	if err != nil {
+		return db.Invite{}, "", err
+	}
+	if invite.Email.Valid && !strings.EqualFold(invite.Email.String, email) {
+		return db.Invite{}, "This invite is for a different email address", nil
+	}
+	return invite, "", nil
+}
+
+type InviteResponse struct {
+	ID        string     `json:"id"`
+	Code      string     `json:"code,omitempty"`
+	Link      string     `json:"link,omitempty"`
+	InviterID string     `json:"inviter_id,omitempty"`
+	Email     string     `json:"email,omitempty"`
+	MaxUses   int32      `json:"max_uses"`
+	Uses      int32      `json:"uses"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func newInviteResponse(invite db.Invite) InviteResponse {
+	response := InviteResponse{
+		ID:        invite.ID.String(),
+		Email:     invite.Email.String,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		ExpiresAt: invite.ExpiresAt,
+		CreatedAt: invite.CreatedAt,
+	}
+	if invite.InviterID.Valid {
+		response.InviterID = invite.InviterID.UUID.String()
+	}
+	if invite.RevokedAt.Valid {
+		response.RevokedAt = &invite.RevokedAt.Time
+	}
+	return response
+}
+
+type CreateInviteRequest struct {
+	Email          string `json:"email"`
+	MaxUses        int    `json:"max_uses"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+	SendEmail      bool   `json:"send_email"`
+}
+
+// createInvite issues an invite code. An invite bound to an email address
+// can only be used once, by that address, and can be emailed straight away.
+// The code is only returned here; just its hash is kept.
+func (s *Server) createInvite(w http.ResponseWriter, r *http.Request) {
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if !s.canInvite(user) {
+		s.writeError(w, r, http.StatusForbidden, problem.CodeForbidden, "Forbidden")
+		return
+	}
+	if s.registrationConfig.Mode == config.RegistrationClosed {
+		s.writeError(w, r, http.StatusConflict, problem.CodeRegistrationClosed, "Registration is closed")
+		return
+	}
+
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.MaxUses < 1 || req.MaxUses > s.registrationConfig.MaxInviteUses {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, fmt.Sprintf("An invite can be used between 1 and %d times", s.registrationConfig.MaxInviteUses))
+		return
+	}
+	if req.Email != "" && req.MaxUses != 1 {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "An invite for an email address can only be used once")
+		return
+	}
+	if req.SendEmail && req.Email == "" {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "An email address is required to send the invite")
+		return
+	}
+
+	ttl := s.registrationConfig.InviteTTL
+	if req.ExpiresInHours != 0 {
+		requested := time.Duration(req.ExpiresInHours) * time.Hour
+		if requested < 0 || requested > ttl {
+			s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, fmt.Sprintf("An invite can last at most %d hours", int(ttl.Hours())))
+			return
+		}
+		ttl = requested
+	}
+
+	code, hash, err := service.GenerateToken()
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	invite, err := s.db.CreateInvite(r.Context(), db.CreateInviteParams{
+		CodeHash:  hash,
+		InviterID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Email:     sql.NullString{String: req.Email, Valid: req.Email != ""},
+		MaxUses:   int32(req.MaxUses),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditInviteCreated, map[string]interface{}{
+		"invite_id": invite.ID,
+		"email":     req.Email,
+		"max_uses":  req.MaxUses,
+	})
+
+	if req.SendEmail {
+		go func() {
+			if err := s.emailService.SendInvite(req.Email, user.Username, code, ttl); err != nil {
+				log.Printf("Failed to send invite email: %v", err)
+			}
+		}()
+	}
+
+	response := newInviteResponse(invite)
+	response.Code = code
+	response.Link = "/register?invite=" + url.QueryEscape(code)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// listInvites returns the invites the caller has created. Users who may
+// not invite get a 403, which tells the dashboard to hide invites.
+func (s *Server) listInvites(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if !s.canInvite(user) {
+		s.writeError(w, r, http.StatusForbidden, problem.CodeForbidden, "Forbidden")
+		return
+	}
+
+	invites, err := s.db.ListInvitesByInviter(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	writeInvites(w, invites)
+}
+
+// adminListInvites returns every invite.
+func (s *Server) adminListInvites(w http.ResponseWriter, r *http.Request) {
+	invites, err := s.db.ListInvites(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	writeInvites(w, invites)
+}
+
+func writeInvites(w http.ResponseWriter, invites []db.Invite) {
+	response := make([]InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		response = append(response, newInviteResponse(invite))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// revokeInvite stops an invite from being used again. Users can revoke their
+// own invites; admins can revoke any.
+func (s *Server) revokeInvite(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Invite not found")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	invite, err := s.db.GetInvite(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.Role != RoleAdmin && invite.InviterID.UUID != user.ID) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Invite not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if err := s.db.RevokeInvite(r.Context(), id); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditInviteRevoked, map[string]interface{}{"invite_id": id})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getRegistrationMode tells signup forms whether to ask for an invite.
+func (s *Server) getRegistrationMode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mode": s.registrationConfig.Mode,
+	})
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// magicLinkNonceCookie binds a sign-in link to the browser that asked for
+// it. The link alone is not enough to sign in, so a link forwarded, leaked
+// from a mailbox or opened on another device is useless.
+const magicLinkNonceCookie = "magic_nonce"
+
+// requestMagicLink emails a one-time sign-in link. Like forgotPassword it
+// always answers 202, and the nonce cookie is set either way, so the
+// response does not reveal whether the address has an account.
+func (s *Server) requestMagicLink(w http.ResponseWriter, r *http.Request) {
+	if !s.magicLinkConfig.Enabled {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Sign-in links are not enabled")
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	nonce, nonceHash, err := service.GenerateToken()
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	// Requests over the limit are dropped silently, like those for unknown
+	// addresses
+	user, err := s.findUserByIdentifier(r.Context(), req.Email)
+	if err == nil {
+		allowed, err := s.magicLinkAllowed(r, user)
+		if err != nil {
+			log.Printf("Failed to check magic link limit: %v", err)
+		} else if allowed {
+			if err := s.startMagicLink(r, user, nonceHash); err != nil {
+				log.Printf("Failed to start magic link login: %v", err)
+			}
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Failed to look up user for magic link: %v", err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkNonceCookie,
+		Value:    nonce,
+		Path:     "/api/login/magic",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		MaxAge:   int(s.magicLinkConfig.TokenTTL.Seconds()),
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// magicLinkAllowed reports whether another sign-in link may be emailed to
+// user. Anyone can ask for one, so without a limit the endpoint would fill
+// the user's inbox and the token table.
+func (s *Server) magicLinkAllowed(r *http.Request, user db.User) (bool, error) {
+	return sendAllowed(func(since time.Time) (int64, error) {
+		return s.db.CountMagicLinkTokensSince(r.Context(), db.CountMagicLinkTokensSinceParams{
+			UserID: user.ID,
+			Since:  since,
+		})
+	}, s.magicLinkConfig.ResendInterval, s.magicLinkConfig.DailyLimit)
+}
+
+func (s *Server) startMagicLink(r *http.Request, user db.User, nonceHash string) error {
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.CreateMagicLinkToken(r.Context(), db.CreateMagicLinkTokenParams{
+		UserID:    user.ID,
+		TokenHash: hash,
+		NonceHash: nonceHash,
+		ExpiresAt: time.Now().Add(s.magicLinkConfig.TokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.emailService.SendMagicLinkEmail(user.Email, token, s.magicLinkConfig.TokenTTL); err != nil {
+			log.Printf("Failed to send magic link email: %v", err)
+		}
+	}()
+	return nil
+}
+
+// verifyMagicLink signs in with a token from a magic link. The emailed link
+// opens the login page, which posts the token here; consuming it on a POST
+// rather than on the GET keeps mail scanners that prefetch links from
+// burning it.
+func (s *Server) verifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	if !s.magicLinkConfig.Enabled {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Sign-in links are not enabled")
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	nonce, err := r.Cookie(magicLinkNonceCookie)
+	if err != nil || req.Token == "" {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidToken, "Sign-in link is invalid or has expired")
+		return
+	}
+
+	userID, err := s.db.ConsumeMagicLinkToken(r.Context(), db.ConsumeMagicLinkTokenParams{
+		TokenHash: service.HashToken(req.Token),
+		NonceHash: service.HashToken(nonce.Value),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidToken, "Sign-in link is invalid or has expired")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkNonceCookie,
+		Value:    "",
+		Path:     "/api/login/magic",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		MaxAge:   -1,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	user, err := s.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	// Following the link proves control of the mailbox
+	if !user.EmailVerified {
+		if err := s.db.MarkEmailVerified(r.Context(), user.ID); err != nil {
+			log.Printf("Failed to mark email verified: %v", err)
+		} else {
+			user.EmailVerified = true
+		}
+	}
+
+	if code, reason := s.loginBlockedReason(user); code != "" {
+		s.writeError(w, r, http.StatusForbidden, code, reason)
+		return
+	}
+
+	if err := s.db.InvalidateMagicLinkTokens(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to invalidate magic link tokens: %v", err)
+	}
+	s.audit(r, user.ID, AuditMagicLinkLogin, nil)
+
+	if s.mfaRequired(user) {
+		s.startMFA(w, r, user, MFAMethodEmail)
+		return
+	}
+	s.completeLogin(w, r, user)
+}
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/middleware"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// mfaPendingTokenDuration is how long a user has to complete the second
+// factor after the password was accepted.
+const mfaPendingTokenDuration = 5 * time.Minute
+
+// Second factor methods
+const (
+	MFAMethodEmail    = "email"
+	MFAMethodTOTP     = "totp"
+	MFAMethodRecovery = "recovery"
+	MFAMethodPasskey  = "passkey"
+	MFAMethodPhone    = "phone"
+)
+
+// authMethodPassword is recorded as the first factor of a password login.
+// Logins through the mailbox record MFAMethodEmail instead.
+const authMethodPassword = "password"
+
+// mfaRequired reports whether user has turned on a second factor.
+//
+// Passkeys do not count. A passkey already signs in on its own, without a
+// password, so registering one is not taken as asking for a second step on
+// password logins; it is only accepted as the second factor while one of
+// the factors here is on.
+func (s *Server) mfaRequired(user db.User) bool {
+	return totpEnabled(user) || user.EmailOtpMfaEnabled || (user.PhoneMfaEnabled && phoneVerified(user))
+}
+
+// mfaMethods lists the second factors user can complete a login with.
+// Passkeys are offered once two-step login is on, but as mfaRequired
+// explains, registering one does not turn it on by itself.
+func (s *Server) mfaMethods(r *http.Request, user db.User) ([]string, error) {
+	var methods []string
+	if totpEnabled(user) {
+		methods = append(methods, MFAMethodTOTP, MFAMethodRecovery)
+	}
+	if user.EmailOtpMfaEnabled {
+		methods = append(methods, MFAMethodEmail)
+	}
+	if user.PhoneMfaEnabled && phoneVerified(user) {
+		methods = append(methods, MFAMethodPhone)
+	}
+	if len(methods) == 0 {
+		return methods, nil
+	}
+
+	passkeys, err := s.db.ListUserWebAuthnCredentials(r.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(passkeys) > 0 {
+		methods = append(methods, MFAMethodPasskey)
+	}
+	return methods, nil
+}
+
+// startMFA answers a login whose first factor was accepted with a token
+// that is only good for completing the second factor. An email code is sent
+// straight away only when there is no authenticator app to use instead;
+// phone codes wait until the client asks for them.
+//
+// firstFactor is the method that was accepted. It is recorded in the token
+// and left out of the methods offered: a login through the mailbox cannot
+// be completed with another code from the same mailbox.
+func (s *Server) startMFA(w http.ResponseWriter, r *http.Request, user db.User, firstFactor string) {
+	methods, err := s.mfaMethods(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	methods = slices.DeleteFunc(methods, func(method string) bool { return method == firstFactor })
+	if len(methods) == 0 {
+		s.writeError(w, r, http.StatusForbidden, problem.CodeMFAUnavailable, "Your second factor is this email address; sign in with your password instead")
+		return
+	}
+	if user.EmailOtpMfaEnabled && !totpEnabled(user) && firstFactor != MFAMethodEmail {
+		// Over the limit, the code sent last is still the one to enter
+		allowed, err := s.emailCodeAllowed(r, user)
+		if err != nil {
+			log.Printf("Failed to check email code limit: %v", err)
+		} else if allowed {
+			if err := s.sendEmailOTP(r, user, otpPurposeMFA); err != nil {
+				log.Printf("Failed to send MFA code: %v", err)
+			}
+		}
+	}
+
+	if _, err := s.startSession(w, r, user, service.ScopeMFAPending, mfaPendingTokenDuration, firstFactor); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Failed to create token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoginResponse{
+		ID:          user.ID.String(),
+		Username:    user.Username,
+		Email:       user.Email,
+		MFARequired: true,
+		MFAMethods:  methods,
+	})
+}
+
+type VerifyMFARequest struct {
+	Method      string          `json:"method"`
+	Code        string          `json:"code"`
+	ChallengeID string          `json:"challenge_id"`
+	Credential  json.RawMessage `json:"credential"`
+}
+
+// verifyMFA completes a login with the second factor. The pending session
+// is swapped for a full one.
+func (s *Server) verifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req VerifyMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	if !s.checkSecondFactor(w, r, user, req) {
+		return
+	}
+
+	if err := s.db.RevokeSession(r.Context(), currentSessionID(r)); err != nil {
+		log.Printf("Failed to revoke MFA pending session: %v", err)
+	}
+	s.audit(r, user.ID, AuditMFACompleted, map[string]interface{}{"method": req.Method})
+
+	s.completeLogin(w, r, user)
+}
+
+// checkSecondFactor reports whether req completes user's second factor. When
+// it does not, the reason has already been written to w.
+func (s *Server) checkSecondFactor(w http.ResponseWriter, r *http.Request, user db.User, req VerifyMFARequest) bool {
+	var (
+		valid bool
+		err   error
+	)
+	switch {
+	case usedFirstFactor(r, req.Method):
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "The first factor cannot also be the second")
+		return false
+	case req.Method == MFAMethodEmail && user.EmailOtpMfaEnabled:
+		valid, err = s.checkEmailOTP(r, user, otpPurposeMFA, req.Code)
+	case req.Method == MFAMethodPhone && user.PhoneMfaEnabled && phoneVerified(user):
+		valid, err = s.checkPhoneOTP(r, user, otpPurposePhoneMFA, req.Code)
+	case req.Method == MFAMethodTOTP && totpEnabled(user):
+		valid, err = s.checkTOTP(r, user, req.Code)
+	case req.Method == MFAMethodRecovery && totpEnabled(user):
+		valid, err = s.checkRecoveryCode(r, user, req.Code)
+	case req.Method == MFAMethodPasskey && s.mfaRequired(user):
+		valid, err = s.checkPasskey(r, user, req.ChallengeID, req.Credential)
+	default:
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Unsupported MFA method")
+		return false
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return false
+	}
+	if !valid {
+		if req.Method != MFAMethodPasskey {
+			locked, err := s.recordMFAFailure(r, user)
+			if err != nil {
+				s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+				return false
+			}
+			if locked {
+				s.writeError(w, r, http.StatusForbidden, problem.CodeAccountLocked, "Too many wrong codes. Reset your password to unlock your account")
+				return false
+			}
+		}
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCode, "Invalid or expired code")
+		return false
+	}
+
+	if err := s.db.ResetMFAFailedAttempts(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to reset MFA failed attempts: %v", err)
+	}
+	return true
+}
+
+// recordMFAFailure counts a wrong second factor code. A single emailed or
+// texted code is burned after a few guesses, but a new one is only a resend
+// away, and authenticator and recovery codes never burn at all, so failures
+// of every kind are counted on the user until a login succeeds. Once they reach the limit the account is
+// locked and signed out everywhere; recordMFAFailure reports whether that
+// happened.
+func (s *Server) recordMFAFailure(r *http.Request, user db.User) (bool, error) {
+	attempts, err := s.db.IncrementMFAFailedAttempts(r.Context(), user.ID)
+	if err != nil {
+		return false, err
+	}
+	if int(attempts) < s.totpConfig.MaxAttempts {
+		return false, nil
+	}
+
+	if err := s.db.LockUser(r.Context(), user.ID); err != nil {
+		return false, err
+	}
+	if err := s.db.RevokeUserSessions(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to revoke sessions: %v", err)
+	}
+	s.audit(r, user.ID, AuditAccountLocked, map[string]interface{}{"reason": "mfa_attempts"})
+	return true, nil
+}
+
+// usedFirstFactor reports whether the pending login was started with
+// method, which then cannot complete it as well.
+func usedFirstFactor(r *http.Request, method string) bool {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	return ok && slices.Contains(claims.AMR, method)
+}
+
+// resendMFACode emails a fresh second factor code during a pending login.
+func (s *Server) resendMFACode(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if !user.EmailOtpMfaEnabled || usedFirstFactor(r, MFAMethodEmail) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeMethodNotEnabled, "Email codes are not enabled")
+		return
+	}
+
+	allowed, err := s.emailCodeAllowed(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !allowed {
+		s.writeError(w, r, http.StatusTooManyRequests, problem.CodeRateLimited, "Too many codes sent; try again later")
+		return
+	}
+
+	if err := s.sendEmailOTP(r, user, otpPurposeMFA); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type SetEmailMFARequest struct {
+	Enabled  bool   `json:"enabled"`
+	Password string `json:"password"`
+}
+
+// setEmailMFA turns emailed codes as a second factor on or off.
+func (s *Server) setEmailMFA(w http.ResponseWriter, r *http.Request) {
+	var req SetEmailMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+	if req.Enabled && !user.EmailVerified {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Verify your email address first")
+		return
+	}
+
+	if err := s.db.SetEmailOTPMFAEnabled(r.Context(), db.SetEmailOTPMFAEnabledParams{
+		ID:                 user.ID,
+		EmailOtpMfaEnabled: req.Enabled,
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	event := AuditMFADisabled
+	if req.Enabled {
+		event = AuditMFAEnabled
+	}
+	s.audit(r, user.ID, event, map[string]interface{}{"method": MFAMethodEmail})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getMFAStatus lists the second factors the caller has enabled and how many
+// recovery codes they have left.
+func (s *Server) getMFAStatus(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	methods, err := s.mfaMethods(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if methods == nil {
+		methods = []string{}
+	}
+
+	var remaining int64
+	if totpEnabled(user) {
+		remaining, err = s.db.CountUnusedRecoveryCodes(r.Context(), user.ID)
+		if err != nil {
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"methods":                  methods,
+		"totp_available":           s.totp != nil,
+		"recovery_codes_remaining": remaining,
+	})
+}
@@ -0,0 +1,539 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+)
+
+// WebAuthn ceremony purposes. A challenge only completes the ceremony it was
+// issued for.
+const (
+	passkeyPurposeRegister = "register"
+	passkeyPurposeLogin    = "login"
+	passkeyPurposeMFA      = "mfa"
+)
+
+// maxPasskeyNameLength matches webauthn_credentials.name.
+const maxPasskeyNameLength = 100
+
+var errPasskeyChallenge = errors.New("invalid or expired passkey challenge")
+
+// webAuthnUser adapts a user and their passkeys to webauthn.User. The user
+// handle is the account's UUID, which carries no personal data.
+type webAuthnUser struct {
+	user        db.User
+	credentials []webauthn.Credential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// loadWebAuthnUser pairs user with their registered passkeys.
+func (s *Server) loadWebAuthnUser(r *http.Request, user db.User) (*webAuthnUser, error) {
+	rows, err := s.db.ListUserWebAuthnCredentials(r.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(rows))
+	for _, row := range rows {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(row.Transports))
+		for _, transport := range row.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              row.CredentialID,
+			PublicKey:       row.PublicKey,
+			AttestationType: row.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				UserPresent:    true,
+				UserVerified:   row.UserVerified,
+				BackupEligible: row.BackupEligible,
+				BackupState:    row.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    row.Aaguid,
+				SignCount: uint32(row.SignCount),
+			},
+		})
+	}
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+// saveChallenge stores the server half of a ceremony and returns the ID the
+// client has to send back with its response.
+func (s *Server) saveChallenge(r *http.Request, userID uuid.NullUUID, purpose string, session *webauthn.SessionData) (uuid.UUID, error) {
+	// Abandoned ceremonies are cleared out here rather than by a job
+	if err := s.db.DeleteExpiredWebAuthnChallenges(r.Context()); err != nil {
+		log.Printf("Failed to delete expired passkey challenges: %v", err)
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return s.db.CreateWebAuthnChallenge(r.Context(), db.CreateWebAuthnChallengeParams{
+		UserID:      userID,
+		Purpose:     purpose,
+		SessionData: data,
+		ExpiresAt:   time.Now().Add(s.webAuthnConfig.ChallengeTTL),
+	})
+}
+
+// takeChallenge loads and deletes a stored ceremony, so every challenge can
+// be answered only once.
+func (s *Server) takeChallenge(r *http.Request, id, purpose string) (uuid.NullUUID, webauthn.SessionData, error) {
+	var session webauthn.SessionData
+
+	challengeID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.NullUUID{}, session, errPasskeyChallenge
+	}
+	row, err := s.db.ConsumeWebAuthnChallenge(r.Context(), db.ConsumeWebAuthnChallengeParams{
+		ID:      challengeID,
+		Purpose: purpose,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.NullUUID{}, session, errPasskeyChallenge
+	}
+	if err != nil {
+		return uuid.NullUUID{}, session, err
+	}
+
+	if err := json.Unmarshal(row.SessionData, &session); err != nil {
+		return uuid.NullUUID{}, session, err
+	}
+	return row.UserID, session, nil
+}
+
+// recordPasskeyUse stores the signature counter after a successful
+// assertion. A counter that went backwards means the authenticator may have
+// been cloned, and the assertion is refused.
+func (s *Server) recordPasskeyUse(r *http.Request, credential *webauthn.Credential) (bool, error) {
+	if credential.Authenticator.CloneWarning {
+		return false, nil
+	}
+
+	err := s.db.UpdateWebAuthnCredentialUsage(r.Context(), db.UpdateWebAuthnCredentialUsageParams{
+		CredentialID: credential.ID,
+		SignCount:    int64(credential.Authenticator.SignCount),
+		BackupState:  credential.Flags.BackupState,
+	})
+	return err == nil, err
+}
+
+// checkPasskey reports whether credential answers the user's pending second
+// factor challenge.
+func (s *Server) checkPasskey(r *http.Request, user db.User, challengeID string, credential json.RawMessage) (bool, error) {
+	owner, session, err := s.takeChallenge(r, challengeID, passkeyPurposeMFA)
+	if errors.Is(err, errPasskeyChallenge) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !owner.Valid || owner.UUID != user.ID {
+		return false, nil
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(credential))
+	if err != nil {
+		return false, nil
+	}
+	waUser, err := s.loadWebAuthnUser(r, user)
+	if err != nil {
+		return false, err
+	}
+	validated, err := s.passkeys.ValidateLogin(waUser, session, parsed)
+	if err != nil {
+		return false, nil
+	}
+	return s.recordPasskeyUse(r, validated)
+}
+
+type PasskeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	Synced     bool       `json:"synced"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func newPasskeyResponse(row db.WebauthnCredential) PasskeyResponse {
+	resp := PasskeyResponse{
+		ID:         row.ID.String(),
+		Name:       row.Name,
+		Transports: row.Transports,
+		Synced:     row.BackupState,
+		CreatedAt:  row.CreatedAt,
+	}
+	if resp.Transports == nil {
+		resp.Transports = []string{}
+	}
+	if row.LastUsedAt.Valid {
+		resp.LastUsedAt = &row.LastUsedAt.Time
+	}
+	return resp
+}
+
+type PasskeyChallengeResponse struct {
+	ChallengeID string      `json:"challenge_id"`
+	Options     interface{} `json:"options"`
+}
+
+// beginPasskeyRegistration starts adding a passkey to the caller's account.
+// Passkeys are created as discoverable credentials so they can also be used
+// to sign in without a username.
+func (s *Server) beginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	var req MFAPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	waUser, err := s.loadWebAuthnUser(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(waUser.credentials))
+	for _, credential := range waUser.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := s.passkeys.BeginRegistration(waUser,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	challengeID, err := s.saveChallenge(r, uuid.NullUUID{UUID: user.ID, Valid: true}, passkeyPurposeRegister, session)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PasskeyChallengeResponse{
+		ChallengeID: challengeID.String(),
+		Options:     creation,
+	})
+}
+
+type FinishPasskeyRegistrationRequest struct {
+	ChallengeID string          `json:"challenge_id"`
+	Name        string          `json:"name"`
+	Credential  json.RawMessage `json:"credential"`
+}
+
+// finishPasskeyRegistration verifies the authenticator's attestation and
+// stores the new passkey.
+func (s *Server) finishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	var req FinishPasskeyRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if len(name) > maxPasskeyNameLength {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Passkey name is too long")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	owner, session, err := s.takeChallenge(r, req.ChallengeID, passkeyPurposeRegister)
+	if errors.Is(err, errPasskeyChallenge) || (err == nil && (!owner.Valid || owner.UUID != user.ID)) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidToken, "Passkey setup expired. Please try again")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(req.Credential))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid passkey response")
+		return
+	}
+	waUser, err := s.loadWebAuthnUser(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	credential, err := s.passkeys.CreateCredential(waUser, session, parsed)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodePasskeyFailed, "Passkey could not be verified")
+		return
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	row, err := s.db.CreateWebAuthnCredential(r.Context(), db.CreateWebAuthnCredentialParams{
+		UserID:          user.ID,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Aaguid:          credential.Authenticator.AAGUID,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		Transports:      transports,
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	})
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditPasskeyAdded, map[string]interface{}{"passkey_id": row.ID.String()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newPasskeyResponse(row))
+}
+
+// listPasskeys returns the caller's registered passkeys.
+func (s *Server) listPasskeys(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rows, err := s.db.ListUserWebAuthnCredentials(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	passkeys := make([]PasskeyResponse, 0, len(rows))
+	for _, row := range rows {
+		passkeys = append(passkeys, newPasskeyResponse(row))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(passkeys)
+}
+
+// deletePasskey removes one of the caller's passkeys.
+func (s *Server) deletePasskey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Passkey not found")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	deleted, err := s.db.DeleteWebAuthnCredential(r.Context(), db.DeleteWebAuthnCredentialParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if deleted == 0 {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Passkey not found")
+		return
+	}
+	s.audit(r, user.ID, AuditPasskeyRemoved, map[string]interface{}{"passkey_id": id.String()})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// beginPasskeyLogin starts a username-less sign-in. The browser offers
+// whichever passkeys it holds for this site.
+func (s *Server) beginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	assertion, session, err := s.passkeys.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	challengeID, err := s.saveChallenge(r, uuid.NullUUID{}, passkeyPurposeLogin, session)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PasskeyChallengeResponse{
+		ChallengeID: challengeID.String(),
+		Options:     assertion,
+	})
+}
+
+type PasskeyAssertionRequest struct {
+	ChallengeID string          `json:"challenge_id"`
+	Credential  json.RawMessage `json:"credential"`
+}
+
+// finishPasskeyLogin signs in with a passkey instead of a password. The
+// passkey requires user verification, so it already counts as two factors
+// and no further MFA step is asked for.
+func (s *Server) finishPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyAssertionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	_, session, err := s.takeChallenge(r, req.ChallengeID, passkeyPurposeLogin)
+	if errors.Is(err, errPasskeyChallenge) {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidToken, "Sign-in expired. Please try again")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(req.Credential))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid passkey response")
+		return
+	}
+
+	findUser := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := uuid.FromBytes(userHandle)
+		if err != nil {
+			return nil, err
+		}
+		user, err := s.db.GetUserByID(r.Context(), userID)
+		if err != nil {
+			return nil, err
+		}
+		return s.loadWebAuthnUser(r, user)
+	}
+	found, credential, err := s.passkeys.ValidatePasskeyLogin(findUser, session, parsed)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodePasskeyFailed, "Passkey could not be verified")
+		return
+	}
+
+	ok, err := s.recordPasskeyUse(r, credential)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !ok {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodePasskeyFailed, "Passkey could not be verified")
+		return
+	}
+
+	user := found.(*webAuthnUser).user
+	if code, reason := s.loginBlockedReason(user); code != "" {
+		s.writeError(w, r, http.StatusForbidden, code, reason)
+		return
+	}
+
+	s.audit(r, user.ID, AuditPasskeyLogin, nil)
+	s.completeLogin(w, r, user)
+}
+
+// beginPasskeyMFA issues a challenge for the pending login's user to answer
+// with one of their passkeys as the second factor. Like verifyMFA it only
+// accepts passkeys while another second factor is on; see mfaRequired.
+func (s *Server) beginPasskeyMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if !s.mfaRequired(user) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeMethodNotEnabled, "Two-step login is not enabled")
+		return
+	}
+
+	waUser, err := s.loadWebAuthnUser(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if len(waUser.credentials) == 0 {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidState, "No passkeys registered")
+		return
+	}
+
+	assertion, session, err := s.passkeys.BeginLogin(waUser)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	challengeID, err := s.saveChallenge(r, uuid.NullUUID{UUID: user.ID, Valid: true}, passkeyPurposeMFA, session)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PasskeyChallengeResponse{
+		ChallengeID: challengeID.String(),
+		Options:     assertion,
+	})
+}
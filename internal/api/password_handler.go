@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// passwordChangeTokenDuration limits how long a restricted token issued at
+// login stays usable for the change-password flow.
+const passwordChangeTokenDuration = 15 * time.Minute
+
+// reusedPasswordViolation is reported alongside policy violations so forms
+// can display it the same way.
+var reusedPasswordViolation = service.PolicyViolation{
+	Rule:    "password_reused",
+	Message: "Password was used recently. Choose a password you have not used before",
+}
+
+// passwordChangeRequired reports whether a user has to rotate their password
+// before getting a full session.
+func (s *Server) passwordChangeRequired(user db.User) bool {
+	return user.MustChangePassword || s.passwordPolicy.Expired(user.PasswordChangedAt)
+}
+
+func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":   "Change Password",
+		"Content": "change_password", // This tells the layout which template to use
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
+		return
+	}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// changePassword accepts both full and password-change tokens. On success the
+// caller gets a full session back, which lifts the restriction.
+func (s *Server) changePassword(w http.ResponseWriter, r *http.Request) {
+	// Swap the restricted session for a full one, signing out anywhere else
+	// the old password was in use
+	user, ok := s.rotatePassword(w, r, uuid.Nil)
+	if !ok {
+		return
+	}
+	if _, err := s.startSession(w, r, user, "", s.jwtConfig.TokenDuration); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Failed to create token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Password changed successfully",
+	})
+}
+
+// changeMyPassword lets a signed-in user pick a new password. The session
+// making the request stays valid; every other session is revoked.
+func (s *Server) changeMyPassword(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.rotatePassword(w, r, currentSessionID(r)); !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Password changed successfully",
+	})
+}
+
+// rotatePassword does the work both change-password endpoints share: it reads
+// a ChangePasswordRequest, checks the current password, stores the new one
+// and revokes every session of the user except keepSession (uuid.Nil revokes
+// all of them). When anything fails it writes the error response and returns
+// false.
+func (s *Server) rotatePassword(w http.ResponseWriter, r *http.Request, keepSession uuid.UUID) (db.User, bool) {
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return db.User{}, false
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Current and new password are required")
+		return db.User{}, false
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return db.User{}, false
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.CurrentPassword, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Current password is incorrect")
+		return db.User{}, false
+	}
+
+	if !s.setNewPassword(w, r, user, req.NewPassword) {
+		return db.User{}, false
+	}
+
+	if err := s.db.RevokeOtherUserSessions(r.Context(), db.RevokeOtherUserSessionsParams{
+		UserID: user.ID,
+		ID:     keepSession,
+	}); err != nil {
+		log.Printf("Failed to revoke sessions: %v", err)
+	}
+	s.audit(r, user.ID, AuditPasswordChanged, nil)
+	s.notifyPasswordChanged(user)
+	return user, true
+}
+
+// setNewPassword checks newPassword against the policy and the password
+// history and stores it for user. When the password is rejected or cannot be
+// stored it writes the error response and returns false.
+func (s *Server) setNewPassword(w http.ResponseWriter, r *http.Request, user db.User, newPassword string) bool {
+	return s.validateNewPassword(w, r, user, newPassword) && s.storeNewPassword(w, r, user, newPassword)
+}
+
+// validateNewPassword writes the policy violations and returns false when
+// newPassword may not be used by user.
+func (s *Server) validateNewPassword(w http.ResponseWriter, r *http.Request, user db.User, newPassword string) bool {
+	if violations := s.passwordPolicy.Evaluate(newPassword, user.Username, user.Email); len(violations) > 0 {
+		s.writePolicyViolations(w, r, violations)
+		return false
+	}
+
+	reused, err := s.passwordReused(r.Context(), user.ID, newPassword)
+	if err != nil {
+		log.Printf("Failed to check password reuse for user %s: %v", user.ID, err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return false
+	}
+	if reused {
+		s.writePolicyViolations(w, r, []service.PolicyViolation{reusedPasswordViolation})
+		return false
+	}
+	return true
+}
+
+// storeNewPassword hashes and saves a password that already passed
+// validateNewPassword.
+func (s *Server) storeNewPassword(w http.ResponseWriter, r *http.Request, user db.User, newPassword string) bool {
+	hashedPassword, err := s.passwordConfig.HashPassword(newPassword)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return false
+	}
+
+	if err := s.db.UpdateUserPassword(r.Context(), db.UpdateUserPasswordParams{
+		ID:                    user.ID,
+		PasswordHash:          hashedPassword,
+		PasswordPolicyVersion: int32(s.passwordPolicy.Version()),
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Failed to update password")
+		return false
+	}
+
+	if err := s.recordPasswordHistory(r.Context(), user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to record password history: %v", err)
+	}
+	return true
+}
+
+// notifyPasswordChanged tells the account owner their password changed, so
+// an unexpected change does not go unnoticed.
+func (s *Server) notifyPasswordChanged(user db.User) {
+	go func() {
+		if err := s.emailService.SendPasswordChangedEmail(user.Email); err != nil {
+			log.Printf("Failed to send password changed email: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+)
+
+// passwordReused reports whether password matches one of the user's last
+// HistorySize passwords. The current password is part of the history, so
+// "changing" to the same password is rejected as well. A history hash that
+// cannot be checked, such as one peppered with a key that is no longer
+// configured, is an error rather than a miss, so old passwords do not become
+// reusable when a key is dropped.
+func (s *Server) passwordReused(ctx context.Context, userID uuid.UUID, password string) (bool, error) {
+	if s.passwordPolicy.HistorySize() <= 0 {
+		return false, nil
+	}
+
+	hashes, err := s.db.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{
+		UserID: userID,
+		Limit:  int32(s.passwordPolicy.HistorySize()),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, hash := range hashes {
+		valid, err := s.passwordConfig.VerifyPassword(password, hash)
+		if err != nil {
+			return false, fmt.Errorf("failed to check password history: %w", err)
+		}
+		if valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordPasswordHistory stores a new password hash and drops entries beyond
+// the configured history size.
+func (s *Server) recordPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	if s.passwordPolicy.HistorySize() <= 0 {
+		return nil
+	}
+
+	if err := s.db.CreatePasswordHistory(ctx, db.CreatePasswordHistoryParams{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}); err != nil {
+		return err
+	}
+
+	return s.db.PrunePasswordHistory(ctx, db.PrunePasswordHistoryParams{
+		UserID: userID,
+		Limit:  int32(s.passwordPolicy.HistorySize()),
+	})
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// passwordResetTokenTTL is how long a reset link stays valid.
+const passwordResetTokenTTL = 30 * time.Minute
+
+func (s *Server) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":   "Reset Password",
+		"Content": "reset_password", // This tells the layout which template to use
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
+		return
+	}
+}
+
+// forgotPassword always answers 202, whether or not the address belongs to an
+// account, so it cannot be used to enumerate users.
+func (s *Server) forgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.db.GetUserByEmail(r.Context(), req.Email)
+	if err == nil {
+		if err := s.startPasswordReset(r, user); err != nil {
+			log.Printf("Failed to start password reset: %v", err)
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Failed to look up user for password reset: %v", err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) startPasswordReset(r *http.Request, user db.User) error {
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.CreatePasswordResetToken(r.Context(), db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.emailService.SendPasswordResetEmail(user.Email, token, passwordResetTokenTTL); err != nil {
+			log.Printf("Failed to send password reset email: %v", err)
+		}
+	}()
+	return nil
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// resetPassword sets a new password from a reset link. Every outstanding
+// reset token and every session of the user is invalidated afterwards.
+func (s *Server) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Token and new password are required")
+		return
+	}
+
+	resetToken, err := s.db.GetPasswordResetToken(r.Context(), service.HashToken(req.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidToken, "Reset link is invalid or has expired")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	user, err := s.db.GetUserByID(r.Context(), resetToken.UserID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if !s.validateNewPassword(w, r, user, req.NewPassword) {
+		return
+	}
+
+	// Claim the token only once the new password is acceptable, so a rejected
+	// password does not burn the link. The conditional update makes sure two
+	// concurrent requests cannot both use it.
+	if _, err := s.db.ConsumePasswordResetToken(r.Context(), resetToken.TokenHash); errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidToken, "Reset link is invalid or has expired")
+		return
+	} else if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if !s.storeNewPassword(w, r, user, req.NewPassword) {
+		return
+	}
+
+	// Proving control of the mailbox is what unlocks an account locked
+	// after a reverted email change
+	if user.LockedAt.Valid {
+		if err := s.db.UnlockUser(r.Context(), user.ID); err != nil {
+			log.Printf("Failed to unlock account: %v", err)
+		}
+	}
+
+	if err := s.db.MarkPasswordResetTokensUsed(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to invalidate password reset tokens: %v", err)
+	}
+	if err := s.db.RevokeUserSessions(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to revoke sessions: %v", err)
+	}
+	s.audit(r, user.ID, AuditPasswordReset, nil)
+	s.notifyPasswordChanged(user)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Password has been reset",
+	})
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// Phone code delivery channels
+const (
+	phoneChannelSMS   = "sms"
+	phoneChannelVoice = "voice"
+)
+
+// phoneOTPPurposes are the codes that cost a text or a call to deliver.
+var phoneOTPPurposes = []string{otpPurposePhoneVerify, otpPurposePhoneMFA}
+
+// phoneCodeAllowed reports whether another code may be texted or called to
+// user. Codes cost money and land on someone's phone, so a new one has to
+// wait ResendInterval after the last and no more than DailyLimit go out in
+// 24 hours.
+func (s *Server) phoneCodeAllowed(r *http.Request, user db.User) (bool, error) {
+	return s.codeAllowed(r, user, phoneOTPPurposes, s.smsConfig.ResendInterval, s.smsConfig.DailyLimit)
+}
+
+// phoneVerified reports whether user has a phone number they have proven
+// they can receive codes on.
+func phoneVerified(user db.User) bool {
+	return user.PhoneNumber.Valid && user.PhoneVerifiedAt.Valid
+}
+
+// sendPhoneOTP texts user a new code for purpose, or calls them with it
+// when voice is set.
+func (s *Server) sendPhoneOTP(r *http.Request, user db.User, purpose string, voice bool) error {
+	code, err := s.issueOTP(r, user, purpose, s.smsConfig.CodeTTL)
+	if err != nil {
+		return err
+	}
+
+	number := user.PhoneNumber.String
+	go func() {
+		var err error
+		if purpose == otpPurposePhoneVerify {
+			err = s.smsService.SendVerificationCode(number, code, s.smsConfig.CodeTTL)
+		} else {
+			err = s.smsService.SendLoginCode(number, code, s.smsConfig.CodeTTL, voice)
+		}
+		if err != nil {
+			log.Printf("Failed to send phone code: %v", err)
+		}
+	}()
+	return nil
+}
+
+// checkPhoneOTP checks a texted code for purpose.
+func (s *Server) checkPhoneOTP(r *http.Request, user db.User, purpose, code string) (bool, error) {
+	return s.checkOTP(r, user, purpose, code, s.smsConfig.MaxAttempts)
+}
+
+type PhoneResponse struct {
+	PhoneNumber string `json:"phone_number"`
+	Verified    bool   `json:"verified"`
+	MFAEnabled  bool   `json:"mfa_enabled"`
+}
+
+// getPhone returns the caller's phone number and whether it is verified.
+func (s *Server) getPhone(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PhoneResponse{
+		PhoneNumber: user.PhoneNumber.String,
+		Verified:    phoneVerified(user),
+		MFAEnabled:  user.PhoneMfaEnabled,
+	})
+}
+
+type SetPhoneRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	Password    string `json:"password"`
+}
+
+// setPhone saves a new phone number for the caller and texts it a
+// verification code. The number cannot be used for sign-in codes until it
+// is verified, so changing it also turns phone codes off.
+func (s *Server) setPhone(w http.ResponseWriter, r *http.Request) {
+	var req SetPhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	number, err := service.NormalizePhoneNumber(req.PhoneNumber)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, err.Error())
+		return
+	}
+
+	allowed, err := s.phoneCodeAllowed(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !allowed {
+		s.writeError(w, r, http.StatusTooManyRequests, problem.CodeRateLimited, "Too many codes sent; try again later")
+		return
+	}
+
+	if err := s.db.SetPhoneNumber(r.Context(), db.SetPhoneNumberParams{
+		ID:          user.ID,
+		PhoneNumber: sql.NullString{String: number, Valid: true},
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if err := s.db.InvalidateOTPs(r.Context(), db.InvalidateOTPsParams{
+		UserID:  user.ID,
+		Purpose: otpPurposePhoneMFA,
+	}); err != nil {
+		log.Printf("Failed to invalidate phone codes: %v", err)
+	}
+	if user.PhoneMfaEnabled {
+		s.audit(r, user.ID, AuditMFADisabled, map[string]interface{}{"method": MFAMethodPhone})
+	}
+	s.audit(r, user.ID, AuditPhoneAdded, map[string]interface{}{"phone_number": service.MaskPhoneNumber(number)})
+
+	user.PhoneNumber = sql.NullString{String: number, Valid: true}
+	if err := s.sendPhoneOTP(r, user, otpPurposePhoneVerify, false); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code"`
+}
+
+// verifyPhone marks the caller's phone number verified once they enter the
+// code texted to it.
+func (s *Server) verifyPhone(w http.ResponseWriter, r *http.Request) {
+	var req VerifyPhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if !user.PhoneNumber.Valid {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Add a phone number first")
+		return
+	}
+	if phoneVerified(user) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	valid, err := s.checkPhoneOTP(r, user, otpPurposePhoneVerify, req.Code)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCode, "Invalid or expired code")
+		return
+	}
+
+	if err := s.db.MarkPhoneVerified(r.Context(), user.ID); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditPhoneVerified, map[string]interface{}{"phone_number": service.MaskPhoneNumber(user.PhoneNumber.String)})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removePhone deletes the caller's phone number and turns phone codes off.
+func (s *Server) removePhone(w http.ResponseWriter, r *http.Request) {
+	var req MFAPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	if err := s.db.SetPhoneNumber(r.Context(), db.SetPhoneNumberParams{ID: user.ID}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if user.PhoneMfaEnabled {
+		s.audit(r, user.ID, AuditMFADisabled, map[string]interface{}{"method": MFAMethodPhone})
+	}
+	if user.PhoneNumber.Valid {
+		s.audit(r, user.ID, AuditPhoneRemoved, map[string]interface{}{"phone_number": service.MaskPhoneNumber(user.PhoneNumber.String)})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type SetPhoneMFARequest struct {
+	Enabled  bool   `json:"enabled"`
+	Password string `json:"password"`
+}
+
+// setPhoneMFA turns texted codes as a second factor on or off.
+func (s *Server) setPhoneMFA(w http.ResponseWriter, r *http.Request) {
+	var req SetPhoneMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+	if !phoneVerified(user) {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Verify your phone number first")
+		return
+	}
+
+	if err := s.db.SetPhoneMFAEnabled(r.Context(), db.SetPhoneMFAEnabledParams{
+		ID:              user.ID,
+		PhoneMfaEnabled: req.Enabled,
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	event := AuditMFADisabled
+	if req.Enabled {
+		event = AuditMFAEnabled
+	}
+	s.audit(r, user.ID, event, map[string]interface{}{"method": MFAMethodPhone})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type SendPhoneCodeRequest struct {
+	Channel string `json:"channel"`
+}
+
+// sendPhoneMFACode texts, or calls with, a second factor code during a
+// pending login. Codes are only sent on request so that users with other
+// methods are not charged for messages they do not need.
+func (s *Server) sendPhoneMFACode(w http.ResponseWriter, r *http.Request) {
+	var req SendPhoneCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	if req.Channel == "" {
+		req.Channel = phoneChannelSMS
+	}
+	if req.Channel != phoneChannelSMS && req.Channel != phoneChannelVoice {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Channel must be sms or voice")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if !user.PhoneMfaEnabled || !phoneVerified(user) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeMethodNotEnabled, "Phone codes are not enabled")
+		return
+	}
+
+	allowed, err := s.phoneCodeAllowed(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !allowed {
+		s.writeError(w, r, http.StatusTooManyRequests, problem.CodeRateLimited, "Too many codes sent; try again later")
+		return
+	}
+
+	if err := s.sendPhoneOTP(r, user, otpPurposePhoneMFA, req.Channel == phoneChannelVoice); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
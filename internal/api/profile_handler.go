@@ -0,0 +1,331 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+	"github.com/yeboahd24/authentication/internal/storage"
+)
+
+// Profile field limits, matching the user_profiles columns
+const (
+	maxDisplayNameLength = 100
+	maxBioLength         = 500
+)
+
+// localePattern accepts BCP 47 style tags such as "en", "en-GB" or
+// "zh-Hant-TW".
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+type ProfileResponse struct {
+	Username    string            `json:"username"`
+	Email       string            `json:"email"`
+	DisplayName string            `json:"display_name"`
+	Bio         string            `json:"bio"`
+	Locale      string            `json:"locale"`
+	Timezone    string            `json:"timezone"`
+	AvatarURLs  map[string]string `json:"avatar_urls,omitempty"`
+}
+
+// UpdateProfileRequest uses pointers so fields left out of the request keep
+// their current value.
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	Locale      *string `json:"locale"`
+	Timezone    *string `json:"timezone"`
+}
+
+// loadProfile returns the user's profile, or an empty one if they have
+// never set it.
+func (s *Server) loadProfile(ctx context.Context, userID uuid.UUID) (db.UserProfile, error) {
+	profile, err := s.db.GetUserProfile(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.UserProfile{UserID: userID}, nil
+	}
+	return profile, err
+}
+
+func (s *Server) profileResponse(user db.User, profile db.UserProfile) ProfileResponse {
+	response := ProfileResponse{
+		Username:    user.Username,
+		Email:       user.Email,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		Locale:      profile.Locale,
+		Timezone:    profile.Timezone,
+	}
+
+	if profile.AvatarKey.Valid {
+		response.AvatarURLs = make(map[string]string, len(s.avatars.Sizes()))
+		version := avatarVersion(profile.AvatarKey.String)
+		for _, size := range s.avatars.Sizes() {
+			response.AvatarURLs[strconv.Itoa(size)] = fmt.Sprintf("/api/users/%s/avatar/%d?v=%s", user.ID, size, version)
+		}
+	}
+	return response
+}
+
+func (s *Server) getMyProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	profile, err := s.loadProfile(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.profileResponse(user, profile))
+}
+
+func (s *Server) updateMyProfile(w http.ResponseWriter, r *http.Request) {
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	profile, err := s.loadProfile(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if req.DisplayName != nil {
+		if utf8.RuneCountInString(*req.DisplayName) > maxDisplayNameLength {
+			s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, fmt.Sprintf("Display name must be at most %d characters", maxDisplayNameLength))
+			return
+		}
+		profile.DisplayName = *req.DisplayName
+	}
+	if req.Bio != nil {
+		if utf8.RuneCountInString(*req.Bio) > maxBioLength {
+			s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, fmt.Sprintf("Bio must be at most %d characters", maxBioLength))
+			return
+		}
+		profile.Bio = *req.Bio
+	}
+	if req.Locale != nil {
+		if *req.Locale != "" && (len(*req.Locale) > 35 || !localePattern.MatchString(*req.Locale)) {
+			s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Invalid locale")
+			return
+		}
+		profile.Locale = *req.Locale
+	}
+	if req.Timezone != nil {
+		if *req.Timezone != "" {
+			if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "Local" {
+				s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Invalid timezone")
+				return
+			}
+		}
+		profile.Timezone = *req.Timezone
+	}
+
+	profile, err = s.db.UpsertUserProfile(r.Context(), db.UpsertUserProfileParams{
+		UserID:      user.ID,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		Locale:      profile.Locale,
+		Timezone:    profile.Timezone,
+	})
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.profileResponse(user, profile))
+}
+
+// uploadAvatar replaces the caller's avatar with the image in the "avatar"
+// form field. Only the generated thumbnails are stored, never the upload.
+func (s *Server) uploadAvatar(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, s.avatars.MaxBytes()+64<<10)
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			s.writeError(w, r, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, service.ErrAvatarTooLarge.Error())
+			return
+		}
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Avatar file is required")
+		return
+	}
+	defer file.Close()
+
+	thumbnails, err := s.avatars.Process(file)
+	switch {
+	case errors.Is(err, service.ErrAvatarTooLarge):
+		s.writeError(w, r, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, err.Error())
+		return
+	case errors.Is(err, service.ErrAvatarType):
+		s.writeError(w, r, http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, err.Error())
+		return
+	case errors.Is(err, service.ErrAvatarDimensions), errors.Is(err, service.ErrAvatarUndecodable):
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to process avatar: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	profile, err := s.loadProfile(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	// Each upload gets a fresh key so cached copies of the old avatar are
+	// never served under the new URL
+	key := fmt.Sprintf("avatars/%s/%s", user.ID, uuid.New())
+	for size, thumbnail := range thumbnails {
+		if err := s.files.Put(r.Context(), avatarObjectKey(key, size), bytes.NewReader(thumbnail)); err != nil {
+			log.Printf("Failed to store avatar: %v", err)
+			s.deleteAvatarFiles(r.Context(), key)
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+			return
+		}
+	}
+
+	if err := s.db.SetUserAvatar(r.Context(), db.SetUserAvatarParams{
+		UserID:    user.ID,
+		AvatarKey: sql.NullString{String: key, Valid: true},
+	}); err != nil {
+		s.deleteAvatarFiles(r.Context(), key)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	if profile.AvatarKey.Valid {
+		s.deleteAvatarFiles(r.Context(), profile.AvatarKey.String)
+	}
+	profile.AvatarKey = sql.NullString{String: key, Valid: true}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.profileResponse(user, profile))
+}
+
+func (s *Server) deleteAvatar(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	profile, err := s.loadProfile(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !profile.AvatarKey.Valid {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := s.db.SetUserAvatar(r.Context(), db.SetUserAvatarParams{UserID: user.ID}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.deleteAvatarFiles(r.Context(), profile.AvatarKey.String)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveAvatar serves a user's avatar thumbnail. Avatars are public.
+func (s *Server) serveAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user ID")
+		return
+	}
+	size, err := strconv.Atoi(chi.URLParam(r, "size"))
+	if err != nil || !s.avatars.HasSize(size) {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid avatar size")
+		return
+	}
+
+	profile, err := s.db.GetUserProfile(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !profile.AvatarKey.Valid) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Avatar not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	object, err := s.files.Open(r.Context(), avatarObjectKey(profile.AvatarKey.String, size))
+	if errors.Is(err, storage.ErrNotFound) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Avatar not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	defer object.Close()
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.URL.Query().Get("v") == avatarVersion(profile.AvatarKey.String) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	io.Copy(w, object)
+}
+
+// deleteAvatarFiles removes every thumbnail stored under key. Failures are
+// logged; a leftover file is harmless once nothing points at it.
+func (s *Server) deleteAvatarFiles(ctx context.Context, key string) {
+	for _, size := range s.avatars.Sizes() {
+		if err := s.files.Delete(ctx, avatarObjectKey(key, size)); err != nil {
+			log.Printf("Failed to delete avatar file: %v", err)
+		}
+	}
+}
+
+func avatarObjectKey(key string, size int) string {
+	return fmt.Sprintf("%s/%d.png", key, size)
+}
+
+// avatarVersion is the last element of an avatar key, which changes with
+// every upload.
+func avatarVersion(key string) string {
+	return path.Base(key)
+}
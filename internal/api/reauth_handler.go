@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+)
+
+type ReauthRequest struct {
+	Password  string `json:"password"`
+	MFAMethod string `json:"mfa_method"`
+	Code      string `json:"code"`
+}
+
+// reauthenticate refreshes the auth_time of the caller's token once they
+// confirm their password, so that endpoints behind RequireRecentAuth let
+// them through again. Users with two-step login on also have to pass their
+// second factor, as they would to log in.
+func (s *Server) reauthenticate(w http.ResponseWriter, r *http.Request) {
+	var req ReauthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Could not confirm it's you")
+		return
+	}
+
+	metadata := map[string]interface{}{"method": authMethodPassword}
+	if s.mfaRequired(user) {
+		if req.MFAMethod == "" {
+			s.requireReauthMFA(w, r, user)
+			return
+		}
+		if req.MFAMethod == MFAMethodPasskey {
+			s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Unsupported MFA method")
+			return
+		}
+		if !s.checkSecondFactor(w, r, user, VerifyMFARequest{Method: req.MFAMethod, Code: req.Code}) {
+			return
+		}
+		metadata["mfa_method"] = req.MFAMethod
+	}
+
+	authTime := time.Now()
+	token, err := s.reissueSessionToken(w, r, user, authTime)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Failed to create token")
+		return
+	}
+	s.audit(r, user.ID, AuditReauthenticated, metadata)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":     token,
+		"auth_time": authTime,
+	})
+}
+
+// requireReauthMFA answers a correct password from a user with two-step
+// login on by asking for the second factor, listed in the mfa_methods
+// member. As with logins, a code is sent straight away when there is no
+// authenticator app to use instead: by email, or by text when that is the
+// only method left.
+func (s *Server) requireReauthMFA(w http.ResponseWriter, r *http.Request, user db.User) {
+	methods, err := s.mfaMethods(r, user)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	methods = slices.DeleteFunc(methods, func(method string) bool { return method == MFAMethodPasskey })
+
+	switch {
+	case totpEnabled(user):
+	case user.EmailOtpMfaEnabled:
+		allowed, err := s.emailCodeAllowed(r, user)
+		if err != nil {
+			log.Printf("Failed to check email code limit: %v", err)
+		} else if allowed {
+			if err := s.sendEmailOTP(r, user, otpPurposeMFA); err != nil {
+				log.Printf("Failed to send MFA code: %v", err)
+			}
+		}
+	case user.PhoneMfaEnabled && phoneVerified(user):
+		allowed, err := s.phoneCodeAllowed(r, user)
+		if err != nil {
+			log.Printf("Failed to check phone code limit: %v", err)
+		} else if allowed {
+			if err := s.sendPhoneOTP(r, user, otpPurposePhoneMFA, false); err != nil {
+				log.Printf("Failed to send MFA code: %v", err)
+			}
+		}
+	}
+
+	s.writeProblem(w, r, problem.New(r, http.StatusUnauthorized, problem.CodeMFARequired, "Enter a code from your second factor to continue").
+		With("mfa_methods", methods))
+}
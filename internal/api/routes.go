@@ -1,23 +1,113 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
-	"github.com/yeboahd24/authentication/internal/middleware"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
 )
 
 func (s *Server) setupRoutes() {
 	// Web routes
 	s.router.Get("/", s.handleHome)
 	s.router.Get("/login", s.handleLogin)
+	s.router.Get("/login/magic", s.handleLogin)
 	s.router.Get("/register", s.handleRegister)
-	s.router.Get("/dashboard", middleware.RequireAuth(s.handleDashboard))
+	s.router.Get("/dashboard", s.auth.RequireAuth(s.handleDashboard))
+	s.router.Get("/verify", s.verifyEmail)
+	s.router.Get("/reset-password", s.handleResetPassword)
+	s.router.Get("/email/confirm", s.confirmEmailChange)
+	s.router.Get("/email/revert", s.revertEmailChange)
+	s.router.Get("/email/identifier/verify", s.verifyIdentifier)
+	s.router.Get("/account/restore", s.restoreAccount)
+	s.router.Get("/admin/approvals", s.auth.RequireAuth(s.handleAdminApprovals))
+	s.router.Get("/change-password", s.auth.RequireAuth(s.handleChangePassword, service.ScopePasswordChange))
+	s.router.Get("/problems/{code}", s.handleProblemType)
+
+	s.router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "Page not found")
+	})
+	s.router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, r, http.StatusMethodNotAllowed, problem.CodeInvalidRequest, "Method not allowed")
+	})
+
+	// Sensitive operations also need a recent login or /api/reauth
+	recentAuth := s.auth.RequireRecentAuth(s.reauthConfig.MaxAge)
 
 	// API routes
 	s.router.Route("/api", func(r chi.Router) {
 		r.Post("/login", s.loginUser)
+		r.Post("/login/magic", s.requestMagicLink)
+		r.Post("/login/magic/verify", s.verifyMagicLink)
+		r.Post("/login/code", s.requestLoginCode)
+		r.Post("/login/code/verify", s.verifyLoginCode)
+		r.Post("/login/mfa", s.auth.RequireAPIAuth(s.verifyMFA, service.ScopeMFAPending))
+		r.Post("/login/mfa/email", s.auth.RequireAPIAuth(s.resendMFACode, service.ScopeMFAPending))
+		r.Post("/login/mfa/phone", s.auth.RequireAPIAuth(s.sendPhoneMFACode, service.ScopeMFAPending))
+		r.Post("/login/mfa/passkey", s.auth.RequireAPIAuth(s.beginPasskeyMFA, service.ScopeMFAPending))
+		r.Post("/login/passkey/begin", s.beginPasskeyLogin)
+		r.Post("/login/passkey/finish", s.finishPasskeyLogin)
+		r.Post("/reauth", s.auth.RequireAPIAuth(s.reauthenticate))
 		r.Post("/register", s.registerUser)
+		r.Get("/registration", s.getRegistrationMode)
+		r.Get("/invites", s.auth.RequireAPIAuth(s.listInvites))
+		r.Post("/invites", s.auth.RequireAPIAuth(recentAuth(s.createInvite)))
+		r.Delete("/invites/{id}", s.auth.RequireAPIAuth(s.revokeInvite))
 		r.Get("/check-username", s.checkUsername)
 		r.Get("/check-email", s.checkEmail)
 		r.Post("/check-password", s.checkPasswordStrength)
+		r.Post("/verify/resend", s.resendVerification)
+		r.Post("/password/forgot", s.forgotPassword)
+		r.Post("/password/reset", s.resetPassword)
+		r.Post("/password/change", s.auth.RequireAPIAuth(s.changePassword, service.ScopePasswordChange))
+
+		r.Get("/users/{id}/avatar/{size}", s.serveAvatar)
+
+		r.Route("/me", func(r chi.Router) {
+			r.Delete("/", s.auth.RequireAPIAuth(recentAuth(s.deleteAccount)))
+			r.Post("/password", s.auth.RequireAPIAuth(recentAuth(s.changeMyPassword)))
+			r.Post("/email", s.auth.RequireAPIAuth(recentAuth(s.requestEmailChange)))
+			r.Get("/emails", s.auth.RequireAPIAuth(s.listEmails))
+			r.Post("/emails", s.auth.RequireAPIAuth(recentAuth(s.addEmail)))
+			r.Post("/emails/{id}/primary", s.auth.RequireAPIAuth(recentAuth(s.makeEmailPrimary)))
+			r.Delete("/emails/{id}", s.auth.RequireAPIAuth(recentAuth(s.deleteEmail)))
+			r.Patch("/username", s.auth.RequireAPIAuth(s.changeUsername))
+			r.Get("/mfa", s.auth.RequireAPIAuth(s.getMFAStatus))
+			r.Put("/mfa/email", s.auth.RequireAPIAuth(recentAuth(s.setEmailMFA)))
+			r.Put("/mfa/phone", s.auth.RequireAPIAuth(recentAuth(s.setPhoneMFA)))
+			r.Post("/mfa/totp", s.auth.RequireAPIAuth(recentAuth(s.startTOTPEnrollment)))
+			r.Get("/mfa/totp/qr", s.auth.RequireAPIAuth(s.getTOTPQRCode))
+			r.Post("/mfa/totp/confirm", s.auth.RequireAPIAuth(recentAuth(s.confirmTOTP)))
+			r.Delete("/mfa/totp", s.auth.RequireAPIAuth(recentAuth(s.disableTOTP)))
+			r.Post("/mfa/recovery-codes", s.auth.RequireAPIAuth(recentAuth(s.regenerateRecoveryCodes)))
+			r.Get("/phone", s.auth.RequireAPIAuth(s.getPhone))
+			r.Put("/phone", s.auth.RequireAPIAuth(recentAuth(s.setPhone)))
+			r.Post("/phone/verify", s.auth.RequireAPIAuth(recentAuth(s.verifyPhone)))
+			r.Delete("/phone", s.auth.RequireAPIAuth(recentAuth(s.removePhone)))
+			r.Get("/passkeys", s.auth.RequireAPIAuth(s.listPasskeys))
+			r.Post("/passkeys/register/begin", s.auth.RequireAPIAuth(recentAuth(s.beginPasskeyRegistration)))
+			r.Post("/passkeys/register/finish", s.auth.RequireAPIAuth(recentAuth(s.finishPasskeyRegistration)))
+			r.Delete("/passkeys/{id}", s.auth.RequireAPIAuth(recentAuth(s.deletePasskey)))
+			r.Get("/profile", s.auth.RequireAPIAuth(s.getMyProfile))
+			r.Patch("/profile", s.auth.RequireAPIAuth(s.updateMyProfile))
+			r.Post("/avatar", s.auth.RequireAPIAuth(s.uploadAvatar))
+			r.Delete("/avatar", s.auth.RequireAPIAuth(s.deleteAvatar))
+			r.Get("/export", s.auth.RequireAPIAuth(s.exportMyData))
+			r.Get("/export/download", s.auth.RequireAPIAuth(s.downloadDataExport))
+			r.Get("/exports/{id}", s.auth.RequireAPIAuth(s.getDataExport))
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Get("/users/{id}/password-policy", s.requireAdmin(s.getUserPasswordPolicy))
+			r.Post("/users/{id}/require-password-change", s.requireAdmin(s.requirePasswordChange))
+			r.Post("/users/{id}/restore", s.requireAdmin(s.adminRestoreAccount))
+			r.Get("/invites", s.requireAdmin(s.adminListInvites))
+			r.Get("/approvals", s.requireAdmin(s.listPendingUsers))
+			r.Post("/users/{id}/approve", s.requireAdmin(s.approveUser))
+			r.Post("/users/{id}/reject", s.requireAdmin(s.rejectUser))
+			r.Post("/users/{id}/suspend", s.requireAdmin(s.suspendUser))
+			r.Post("/users/{id}/reactivate", s.requireAdmin(s.reactivateUser))
+		})
 	})
 }
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"html/template"
 	"log"
 	"net/http"
@@ -8,30 +10,97 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/yeboahd24/authentication/internal/config"
 	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	authmiddleware "github.com/yeboahd24/authentication/internal/middleware"
+	"github.com/yeboahd24/authentication/internal/problem"
 	"github.com/yeboahd24/authentication/internal/service"
+	"github.com/yeboahd24/authentication/internal/storage"
 )
 
 type Server struct {
-	router       *chi.Mux
-	db           *db.Queries
-	jwtMaker     *service.JWTMaker
-	emailService *service.EmailService
-	templates    *template.Template
-	jwtConfig    config.JWTConfig
+	router         *chi.Mux
+	database       *sql.DB
+	db             *db.Queries
+	jwtMaker       *service.JWTMaker
+	auth           *authmiddleware.Auth
+	emailService   *service.EmailService
+	smsService     *service.SMSService
+	passwordPolicy *service.PasswordPolicy
+	emailPolicy    *service.EmailPolicy
+	usernames      *service.UsernamePolicy
+	passwordConfig *service.PasswordConfig
+	avatars        *service.AvatarProcessor
+	files          storage.Storage
+	totp           *service.TOTP
+	passkeys       *webauthn.WebAuthn
+	templates      *template.Template
+	jwtConfig      config.JWTConfig
+
+	verificationConfig config.EmailVerificationConfig
+	emailChangeConfig  config.EmailChangeConfig
+	deletionConfig     config.AccountDeletionConfig
+	exportConfig       config.DataExportConfig
+	usernameConfig     config.UsernameChangeConfig
+	magicLinkConfig    config.MagicLinkConfig
+	emailOTPConfig     config.EmailOTPConfig
+	totpConfig         config.TOTPConfig
+	webAuthnConfig     config.WebAuthnConfig
+	smsConfig          config.SMSConfig
+	reauthConfig       config.ReauthConfig
+	registrationConfig config.RegistrationConfig
 }
 
 func (s *Server) Router() *chi.Mux {
 	return s.router
 }
 
-func NewServer(db *db.Queries, jwtMaker *service.JWTMaker, emailService *service.EmailService) *Server {
+// inTx runs fn with queries bound to a single transaction, which is committed
+// only if fn succeeds.
+func (s *Server) inTx(ctx context.Context, fn func(queries *db.Queries) error) error {
+	tx, err := s.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(s.db.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func NewServer(cfg *config.Config, database *sql.DB, db *db.Queries, jwtMaker *service.JWTMaker, emailService *service.EmailService, smsService *service.SMSService, passwordPolicy *service.PasswordPolicy, emailPolicy *service.EmailPolicy, usernames *service.UsernamePolicy, passwordConfig *service.PasswordConfig, avatars *service.AvatarProcessor, files storage.Storage, totp *service.TOTP, passkeys *webauthn.WebAuthn) *Server {
 	server := &Server{
-		router:       chi.NewRouter(),
-		db:           db,
-		jwtMaker:     jwtMaker,
-		emailService: emailService,
+		router:         chi.NewRouter(),
+		database:       database,
+		db:             db,
+		jwtMaker:       jwtMaker,
+		emailService:   emailService,
+		smsService:     smsService,
+		passwordPolicy: passwordPolicy,
+		emailPolicy:    emailPolicy,
+		usernames:      usernames,
+		passwordConfig: passwordConfig,
+		avatars:        avatars,
+		files:          files,
+		totp:           totp,
+		passkeys:       passkeys,
+		jwtConfig:      cfg.JWT,
+
+		verificationConfig: cfg.EmailVerification,
+		emailChangeConfig:  cfg.EmailChange,
+		deletionConfig:     cfg.AccountDeletion,
+		exportConfig:       cfg.DataExport,
+		usernameConfig:     cfg.UsernameChange,
+		magicLinkConfig:    cfg.MagicLink,
+		emailOTPConfig:     cfg.EmailOTP,
+		totpConfig:         cfg.TOTP,
+		webAuthnConfig:     cfg.WebAuthn,
+		smsConfig:          cfg.SMS,
+		reauthConfig:       cfg.Reauth,
+		registrationConfig: cfg.Registration,
 	}
 
 	// Load templates
@@ -50,7 +119,10 @@ func NewServer(db *db.Queries, jwtMaker *service.JWTMaker, emailService *service
 	}
 	server.templates = templates
 
+	server.auth = authmiddleware.NewAuth(jwtMaker, server.sessionActive)
+
 	// Middleware
+	server.router.Use(middleware.RequestID)
 	server.router.Use(middleware.Logger)
 	server.router.Use(middleware.Recoverer)
 
@@ -80,21 +152,22 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	err := s.templates.ExecuteTemplate(w, "layout.html", nil)
 	if err != nil {
 		log.Printf("Template execution error: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
 		return
 	}
 }
 
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	data := map[string]interface{}{
-		"Title":   "Login",
-		"Content": "login", // This tells the layout which template to use
+		"Title":            "Login",
+		"Content":          "login", // This tells the layout which template to use
+		"MagicLinkEnabled": s.magicLinkConfig.Enabled,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
 		log.Printf("Template execution error: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
 		return
 	}
 }
@@ -108,7 +181,7 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := s.templates.ExecuteTemplate(w, "layout.html", data); err != nil {
 		log.Printf("Template execution error: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
 		return
 	}
 }
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/middleware"
+)
+
+// startSession records a new session for user, issues a token bound to it and
+// stores the token in the session cookie. amr lists the methods the user
+// authenticated with, if the token needs to remember them.
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, user db.User, scope string, duration time.Duration, amr ...string) (string, error) {
+	session, err := s.db.CreateSession(r.Context(), db.CreateSessionParams{
+		UserID:    user.ID,
+		UserAgent: r.UserAgent(),
+		IpAddress: clientIP(r),
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.jwtMaker.CreateSessionToken(session.ID.String(), user.ID.String(), user.Username, scope, time.Now(), duration, amr...)
+	if err != nil {
+		return "", err
+	}
+
+	s.setTokenCookie(w, r, token, duration)
+	return token, nil
+}
+
+// refreshSessionToken reissues the request's token for the same session so
+// the claims reflect changes to user. The new token keeps the old expiry and
+// authentication time.
+func (s *Server) refreshSessionToken(w http.ResponseWriter, r *http.Request, user db.User) (string, error) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		return "", errors.New("request is not authenticated")
+	}
+	return s.reissueSessionToken(w, r, user, claims.AuthenticatedAt())
+}
+
+// reissueSessionToken replaces the request's token with one for the same
+// session and expiry that records authTime as the last authentication.
+func (s *Server) reissueSessionToken(w http.ResponseWriter, r *http.Request, user db.User, authTime time.Time) (string, error) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok || claims.ExpiresAt == nil {
+		return "", errors.New("request is not authenticated")
+	}
+
+	duration := time.Until(claims.ExpiresAt.Time)
+	token, err := s.jwtMaker.CreateSessionToken(claims.ID, user.ID.String(), user.Username, claims.Scope, authTime, duration, claims.AMR...)
+	if err != nil {
+		return "", err
+	}
+
+	s.setTokenCookie(w, r, token, duration)
+	return token, nil
+}
+
+// sessionActive is the middleware.SessionChecker backing token validation.
+func (s *Server) sessionActive(ctx context.Context, sessionID string) bool {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return false
+	}
+
+	active, err := s.db.IsSessionActive(ctx, id)
+	return err == nil && active
+}
+
+// currentUser loads the user the request's token belongs to. It must run
+// behind RequireAuth or RequireAPIAuth.
+func (s *Server) currentUser(r *http.Request) (db.User, error) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		return db.User{}, errors.New("request is not authenticated")
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return db.User{}, err
+	}
+	return s.db.GetUserByID(r.Context(), userID)
+}
+
+// currentSessionID returns the session the request's token is bound to.
+func currentSessionID(r *http.Request) uuid.UUID {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		return uuid.Nil
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
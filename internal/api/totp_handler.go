@@ -0,0 +1,319 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+// totpQRCodeSize is the width and height of the enrollment QR code in pixels.
+const totpQRCodeSize = 256
+
+// totpEnabled reports whether user has a confirmed authenticator app.
+func totpEnabled(user db.User) bool {
+	return user.TotpEnabledAt.Valid && user.TotpSecretEncrypted.Valid
+}
+
+// checkTOTP reports whether code is the user's current authenticator code.
+// A time step is accepted only once, so an observed code cannot be replayed
+// while it is still valid.
+func (s *Server) checkTOTP(r *http.Request, user db.User, code string) (bool, error) {
+	if s.totp == nil || !totpEnabled(user) {
+		return false, nil
+	}
+
+	secret, err := s.totp.DecryptSecret(user.TotpSecretEncrypted.String)
+	if err != nil {
+		return false, err
+	}
+	step, ok := s.totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	used, err := s.db.UseTOTPStep(r.Context(), db.UseTOTPStepParams{
+		ID:               user.ID,
+		TotpLastUsedStep: sql.NullInt64{Int64: step, Valid: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	return used == 1, nil
+}
+
+// checkRecoveryCode reports whether code is one of the user's unused
+// recovery codes and uses it up if so.
+func (s *Server) checkRecoveryCode(r *http.Request, user db.User, code string) (bool, error) {
+	used, err := s.db.UseRecoveryCode(r.Context(), db.UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: service.HashRecoveryCode(code),
+	})
+	if err != nil {
+		return false, err
+	}
+	return used == 1, nil
+}
+
+// issueRecoveryCodes replaces the user's recovery codes with a new set and
+// returns them. Only their hashes are kept, so this is the one chance to
+// show them. Callers run it in a transaction, so a failure never leaves the
+// user with part of a set.
+func issueRecoveryCodes(r *http.Request, queries *db.Queries, user db.User) ([]string, error) {
+	codes, err := service.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := queries.DeleteRecoveryCodes(r.Context(), user.ID); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := queries.CreateRecoveryCode(r.Context(), db.CreateRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: service.HashRecoveryCode(code),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+type MFAPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret    string `json:"secret"`
+	URI       string `json:"otpauth_uri"`
+	QRCodeURL string `json:"qr_code_url"`
+}
+
+// startTOTPEnrollment generates a new authenticator secret for the caller.
+// It is not used for login until confirmTOTP has seen a code from it.
+func (s *Server) startTOTPEnrollment(w http.ResponseWriter, r *http.Request) {
+	if s.totp == nil {
+		s.writeError(w, r, http.StatusServiceUnavailable, problem.CodeUnavailable, "Authenticator apps are not available")
+		return
+	}
+
+	var req MFAPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+	if totpEnabled(user) {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "An authenticator app is already enabled")
+		return
+	}
+
+	secret, err := s.totp.GenerateSecret()
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	encrypted, err := s.totp.EncryptSecret(secret)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if err := s.db.SetPendingTOTPSecret(r.Context(), db.SetPendingTOTPSecretParams{
+		ID:                  user.ID,
+		TotpSecretEncrypted: sql.NullString{String: encrypted, Valid: true},
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(TOTPEnrollmentResponse{
+		Secret:    secret,
+		URI:       s.totp.URI(user.Email, secret),
+		QRCodeURL: "/api/me/mfa/totp/qr",
+	})
+}
+
+// getTOTPQRCode renders the pending enrollment's otpauth URI as a PNG so the
+// secret never has to go through a third-party QR service.
+func (s *Server) getTOTPQRCode(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if s.totp == nil || !user.TotpSecretEncrypted.Valid || totpEnabled(user) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "No authenticator enrollment in progress")
+		return
+	}
+
+	secret, err := s.totp.DecryptSecret(user.TotpSecretEncrypted.String)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	png, err := service.QRCodePNG(s.totp.URI(user.Email, secret), totpQRCodeSize)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+// confirmTOTP enables the pending authenticator once the user proves their
+// app produces the right codes, and hands out the first recovery codes.
+func (s *Server) confirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var req ConfirmTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if s.totp == nil || !user.TotpSecretEncrypted.Valid || totpEnabled(user) {
+		s.writeError(w, r, http.StatusNotFound, problem.CodeNotFound, "No authenticator enrollment in progress")
+		return
+	}
+
+	secret, err := s.totp.DecryptSecret(user.TotpSecretEncrypted.String)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	step, ok := s.totp.Validate(secret, req.Code, time.Now())
+	if !ok {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCode, "Invalid code")
+		return
+	}
+
+	// The authenticator is only switched on together with its recovery
+	// codes
+	var codes []string
+	if err := s.inTx(r.Context(), func(queries *db.Queries) error {
+		if err := queries.EnableTOTP(r.Context(), db.EnableTOTPParams{
+			ID:               user.ID,
+			TotpLastUsedStep: sql.NullInt64{Int64: step, Valid: true},
+		}); err != nil {
+			return err
+		}
+		codes, err = issueRecoveryCodes(r, queries, user)
+		return err
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditMFAEnabled, map[string]interface{}{"method": MFAMethodTOTP})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// disableTOTP removes the caller's authenticator app and recovery codes.
+func (s *Server) disableTOTP(w http.ResponseWriter, r *http.Request) {
+	var req MFAPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+
+	if err := s.db.DisableTOTP(r.Context(), user.ID); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	if err := s.db.DeleteRecoveryCodes(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to delete recovery codes: %v", err)
+	}
+	if totpEnabled(user) {
+		s.audit(r, user.ID, AuditMFADisabled, map[string]interface{}{"method": MFAMethodTOTP})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// regenerateRecoveryCodes replaces the caller's recovery codes, for when the
+// old ones are lost or running out.
+func (s *Server) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req MFAPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	if err != nil || !valid {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Password is incorrect")
+		return
+	}
+	if !totpEnabled(user) {
+		s.writeError(w, r, http.StatusConflict, problem.CodeInvalidState, "Enable an authenticator app first")
+		return
+	}
+
+	var codes []string
+	if err := s.inTx(r.Context(), func(queries *db.Queries) error {
+		codes, err = issueRecoveryCodes(r, queries, user)
+		return err
+	}); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+		return
+	}
+	s.audit(r, user.ID, AuditRecoveryCodesRegenerated, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
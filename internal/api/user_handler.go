@@ -1,183 +1,247 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/yeboahd24/authentication/internal/config"
 	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
 	"github.com/yeboahd24/authentication/internal/service"
 )
 
-const (
-	WeakPassword       = 0
-	MediumPassword     = 1
-	StrongPassword     = 2
-	VeryStrongPassword = 3
-)
-
-func calculatePasswordStrength(password string) int {
-	// Initialize score
-	score := 0
-
-	// Check length
-	if len(password) >= 8 {
-		score++
-	}
-
-	// Check for numbers
-	hasNumber := false
-	for _, char := range password {
-		if char >= '0' && char <= '9' {
-			hasNumber = true
-			break
-		}
-	}
-	if hasNumber {
-		score++
-	}
-
-	// Check for special characters
-	hasSpecial := false
-	for _, char := range password {
-		if (char >= '!' && char <= '/') || (char >= ':' && char <= '@') || (char >= '[' && char <= '`') || (char >= '{' && char <= '~') {
-			hasSpecial = true
-			break
-		}
-	}
-	if hasSpecial {
-		score++
-	}
-
-	// Check for mixed case
-	hasUpper := false
-	hasLower := false
-	for _, char := range password {
-		if char >= 'A' && char <= 'Z' {
-			hasUpper = true
+// checkUsername runs a username through the username policy and, if it
+// passes, checks that it is neither taken nor confusable with an existing
+// one. The normalized form is returned so forms can show what will be saved.
+func (s *Server) checkUsername(w http.ResponseWriter, r *http.Request) {
+	username := s.usernames.Normalize(r.URL.Query().Get("username"))
+	violations := s.usernames.Evaluate(username)
+	if len(violations) == 0 {
+		violation, err := s.usernameTaken(r, username)
+		if err != nil {
+			log.Printf("Failed to check username: %v", err)
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+			return
 		}
-		if char >= 'a' && char <= 'z' {
-			hasLower = true
+		if violation != nil {
+			violations = append(violations, *violation)
 		}
 	}
-	if hasUpper && hasLower {
-		score++
-	}
 
-	return score
-}
-
-func getPasswordFeedback(strength int) string {
-	switch strength {
-	case WeakPassword:
-		return "Password is too weak. Use at least 8 characters with numbers, special characters, and mixed case."
-	case MediumPassword:
-		return "Password could be stronger. Try adding special characters or mixed case."
-	case StrongPassword:
-		return "Good password strength."
-	case VeryStrongPassword:
-		return "Excellent password strength!"
-	default:
-		return "Invalid password strength"
-	}
-}
-
-func (s *Server) checkUsername(w http.ResponseWriter, r *http.Request) {
-	username := r.URL.Query().Get("username")
-	exists, err := s.db.CheckUsernameExists(r.Context(), username)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	if exists {
-		w.Write([]byte(`<p class="mt-1 text-sm text-red-600">Username already taken</p>`))
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":      len(violations) == 0,
+		"username":   username,
+		"violations": violations,
+	})
 }
 
+// checkEmail runs an address through the email policy and, if it passes,
+// checks it is not already taken, so the signup form can flag problems as
+// the user types.
 func (s *Server) checkEmail(w http.ResponseWriter, r *http.Request) {
 	email := r.URL.Query().Get("email")
-	exists, err := s.db.CheckEmailExists(r.Context(), email)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	violations := s.emailPolicy.Evaluate(r.Context(), email)
+	if len(violations) == 0 {
+		exists, err := s.db.CheckEmailExists(r.Context(), email)
+		if err != nil {
+			log.Printf("Failed to check email: %v", err)
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+			return
+		}
+		if exists {
+			violations = append(violations, service.PolicyViolation{Rule: "taken", Message: "Email already registered"})
+		}
 	}
 
-	w.Header().Set("Content-Type", "text/html")
-	if exists {
-		w.Write([]byte(`<p class="mt-1 text-sm text-red-600">Email already registered</p>`))
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":      len(violations) == 0,
+		"violations": violations,
+	})
 }
 
 func (s *Server) checkPasswordStrength(w http.ResponseWriter, r *http.Request) {
 	var password struct {
 		Password string `json:"password"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&password); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
 		return
 	}
 
-	// Implement password strength checking logic
-	strength := calculatePasswordStrength(password.Password)
+	strength := service.EstimatePasswordStrength(password.Password)
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"strength": strength,
-		"feedback": getPasswordFeedback(strength),
+		"strength":   strength,
+		"feedback":   service.PasswordFeedback(strength),
+		"violations": s.passwordPolicy.Evaluate(password.Password, password.Username, password.Email),
 	})
 }
 
+// LoginRequest identifies the account by Identifier, which may be a username
+// or any verified email address on the account. Email is still accepted for
+// older clients.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Identifier string `json:"identifier"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
 }
 
 type LoginResponse struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Token    string `json:"token"`
+	ID                     string   `json:"id"`
+	Username               string   `json:"username"`
+	Email                  string   `json:"email"`
+	Token                  string   `json:"token,omitempty"`
+	PasswordChangeRequired bool     `json:"password_change_required,omitempty"`
+	MFARequired            bool     `json:"mfa_required,omitempty"`
+	MFAMethods             []string `json:"mfa_methods,omitempty"`
+}
+
+// clearTokenCookie removes the session cookie from the browser.
+func (s *Server) clearTokenCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "token",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		MaxAge:   -1,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// setTokenCookie stores the session token in an HTTP-only cookie.
+func (s *Server) setTokenCookie(w http.ResponseWriter, r *http.Request, token string, duration time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil, // Set to true if using HTTPS
+		MaxAge:   int(duration.Seconds()),
+		SameSite: http.SameSiteLaxMode,
+	})
 }
 
 func (s *Server) loginUser(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
 		return
 	}
 
-	// Get user from database by email
-	user, err := s.db.GetUserByEmail(r.Context(), req.Email)
+	identifier := req.Identifier
+	if identifier == "" {
+		identifier = req.Email
+	}
+	user, err := s.findUserByIdentifier(r.Context(), identifier)
 	if err != nil {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Invalid credentials")
 		return
 	}
 
 	// Verify password
-	passwordConfig := service.NewPasswordConfig()
-	valid, err := passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
+	valid, err := s.passwordConfig.VerifyPassword(req.Password, user.PasswordHash)
 	if err != nil || !valid {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Invalid credentials")
 		return
 	}
 
-	// Generate JWT token
-	token, err := s.jwtMaker.CreateToken(user.ID.String(), user.Username, s.jwtConfig.TokenDuration)
-	if err != nil {
-		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+	if code, reason := s.loginBlockedReason(user); code != "" {
+		s.writeError(w, r, http.StatusForbidden, code, reason)
 		return
 	}
 
-	// Set token as HTTP-only cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "token",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   r.TLS != nil, // Set to true if using HTTPS
-		MaxAge:   int(s.jwtConfig.TokenDuration.Seconds()),
-		SameSite: http.SameSiteLaxMode,
-	})
+	// Re-pepper hashes made with a retired pepper key. The password itself is
+	// unchanged, so password_changed_at is left alone
+	if s.passwordConfig.NeedsRehash(user.PasswordHash) {
+		if rehashed, err := s.passwordConfig.HashPassword(req.Password); err != nil {
+			log.Printf("Failed to rehash password: %v", err)
+		} else if err := s.db.UpdatePasswordHash(r.Context(), db.UpdatePasswordHashParams{
+			ID:           user.ID,
+			PasswordHash: rehashed,
+		}); err != nil {
+			log.Printf("Failed to store rehashed password: %v", err)
+		}
+	}
+
+	// Record that the password satisfies a newer policy, if it does
+	if int(user.PasswordPolicyVersion) < s.passwordPolicy.Version() &&
+		len(s.passwordPolicy.Evaluate(req.Password, user.Username, user.Email)) == 0 {
+		if err := s.db.UpdatePasswordPolicyVersion(r.Context(), db.UpdatePasswordPolicyVersionParams{
+			ID:                    user.ID,
+			PasswordPolicyVersion: int32(s.passwordPolicy.Version()),
+		}); err != nil {
+			log.Printf("Failed to update password policy version: %v", err)
+		}
+	}
+
+	if s.mfaRequired(user) {
+		s.startMFA(w, r, user, authMethodPassword)
+		return
+	}
+
+	s.completeLogin(w, r, user)
+}
+
+// loginBlockedReason returns the problem code and message explaining why
+// user may not sign in, or "" if they may. Every login method checks it once
+// the user has proven who they are.
+func (s *Server) loginBlockedReason(user db.User) (string, string) {
+	switch user.Status {
+	case UserStatusPendingApproval:
+		return problem.CodeAccountPending, "Account is awaiting approval. You will get an email once an administrator has reviewed it"
+	case UserStatusRejected:
+		return problem.CodeAccountRejected, "Account registration was not approved"
+	case UserStatusSuspended:
+		return problem.CodeAccountSuspended, "Account is suspended. Contact an administrator"
+	}
+	if user.DeletionRequestedAt.Valid {
+		return problem.CodeAccountDeleted, "Account is scheduled for deletion. Use the link in your email to restore it"
+	}
+	if user.LockedAt.Valid {
+		return problem.CodeAccountLocked, "Account is locked. Reset your password to unlock it"
+	}
+	if s.verificationConfig.RequireForLogin && !user.EmailVerified {
+		return problem.CodeEmailNotVerified, "Email address not verified"
+	}
+	return "", ""
+}
+
+// completeLogin starts a session for an authenticated user and writes the
+// login response. Users who have to rotate their password only get a
+// restricted token that can reach the change-password page.
+func (s *Server) completeLogin(w http.ResponseWriter, r *http.Request, user db.User) {
+	if s.passwordChangeRequired(user) {
+		if _, err := s.startSession(w, r, user, service.ScopePasswordChange, passwordChangeTokenDuration); err != nil {
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Failed to create token")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(LoginResponse{
+			ID:                     user.ID.String(),
+			Username:               user.Username,
+			Email:                  user.Email,
+			PasswordChangeRequired: true,
+		})
+		return
+	}
+
+	// Generate JWT token and set it as HTTP-only cookie
+	token, err := s.startSession(w, r, user, "", s.jwtConfig.TokenDuration)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Failed to create token")
+		return
+	}
 
 	// Create response
 	response := LoginResponse{
@@ -192,71 +256,118 @@ func (s *Server) loginUser(w http.ResponseWriter, r *http.Request) {
 }
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	InviteCode string `json:"invite_code"`
 }
 
+// registerUser creates an account if the registration mode allows it. In
+// invite mode a usable invite must come with the request.
 func (s *Server) registerUser(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	// Validate input
+	req.Username = s.usernames.Normalize(req.Username)
 	if req.Email == "" || req.Username == "" || req.Password == "" {
-		http.Error(w, "Email, username and password are required", http.StatusBadRequest)
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Email, username and password are required")
 		return
 	}
-
-	// Check if email already exists
-	emailExists, err := s.db.CheckEmailExists(r.Context(), req.Email)
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if violations := s.usernames.Evaluate(req.Username); len(violations) > 0 {
+		s.writeViolations(w, r, "username", "Username does not meet the username policy", violations)
 		return
 	}
-	if emailExists {
-		http.Error(w, "Email already registered", http.StatusConflict)
+	if violations := s.emailPolicy.Evaluate(r.Context(), req.Email); len(violations) > 0 {
+		s.writeViolations(w, r, "email", "Email address is not allowed", violations)
 		return
 	}
 
-	// Check if username already exists
-	usernameExists, err := s.db.CheckUsernameExists(r.Context(), req.Username)
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-	if usernameExists {
-		http.Error(w, "Username already taken", http.StatusConflict)
+	switch s.registrationConfig.Mode {
+	case config.RegistrationOpen:
+	case config.RegistrationInvite:
+		_, reason, err := s.checkInvite(r, req.InviteCode, req.Email)
+		if err != nil {
+			s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+			return
+		}
+		if reason != "" {
+			s.writeError(w, r, http.StatusForbidden, problem.CodeInviteInvalid, reason)
+			return
+		}
+	default:
+		s.writeError(w, r, http.StatusForbidden, problem.CodeRegistrationClosed, "Registration is closed")
 		return
 	}
 
-	// Check password strength
-	strength := calculatePasswordStrength(req.Password)
-	if strength < MediumPassword {
-		http.Error(w, "Password too weak", http.StatusBadRequest)
+	// Check password against the policy
+	if violations := s.passwordPolicy.Evaluate(req.Password, req.Username, req.Email); len(violations) > 0 {
+		s.writePolicyViolations(w, r, violations)
 		return
 	}
 
 	// Hash password
-	passwordConfig := service.NewPasswordConfig()
-	hashedPassword, err := passwordConfig.HashPassword(req.Password)
+	hashedPassword, err := s.passwordConfig.HashPassword(req.Password)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
 		return
 	}
 
-	// Create user
-	user, err := s.db.CreateUser(r.Context(), db.CreateUserParams{
-		Email:        req.Email,
-		Username:     req.Username,
-		PasswordHash: hashedPassword,
+	status := UserStatusActive
+	if s.registrationConfig.RequireApproval {
+		status = UserStatusPendingApproval
+	}
+
+	// Take a use of the invite and create the user in one transaction, so a
+	// rejected signup never uses the invite up. The database rejects taken
+	// and look-alike identities and serializes the check with advisory
+	// locks, so concurrent signups for the same email or username cannot
+	// both succeed
+	var (
+		invite db.Invite
+		user   db.User
+	)
+	err = s.inTx(r.Context(), func(queries *db.Queries) error {
+		var err error
+		if s.registrationConfig.Mode == config.RegistrationInvite {
+			invite, err = queries.RedeemInvite(r.Context(), service.HashToken(req.InviteCode))
+			if err != nil {
+				return err
+			}
+		}
+		user, err = queries.CreateUser(r.Context(), db.CreateUserParams{
+			Email:                 req.Email,
+			Username:              req.Username,
+			PasswordHash:          hashedPassword,
+			PasswordPolicyVersion: int32(s.passwordPolicy.Version()),
+			Status:                status,
+			UsernameSkeleton:      service.UsernameSkeleton(req.Username),
+		})
+		return err
 	})
+	// Only redeeming the invite can find no row
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeError(w, r, http.StatusForbidden, problem.CodeInviteInvalid, "This invite is invalid or has expired")
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		s.writeDBError(w, r, err)
 		return
 	}
+	if invite.ID != uuid.Nil {
+		s.audit(r, user.ID, AuditInviteRedeemed, map[string]interface{}{"invite_id": invite.ID})
+	}
+
+	if err := s.recordPasswordHistory(r.Context(), user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to record password history: %v", err)
+	}
+
+	if err := s.startEmailVerification(r.Context(), user); err != nil {
+		log.Printf("Failed to start email verification: %v", err)
+	}
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
@@ -265,10 +376,18 @@ func (s *Server) registerUser(w http.ResponseWriter, r *http.Request) {
 		"id":       user.ID,
 		"email":    user.Email,
 		"username": user.Username,
+		"status":   user.Status,
 	})
 }
 
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	// A full session issued before the user was flagged still has to rotate
+	// the password first
+	if user, err := s.currentUser(r); err == nil && s.passwordChangeRequired(user) {
+		http.Redirect(w, r, "/change-password", http.StatusSeeOther)
+		return
+	}
+
 	data := map[string]interface{}{
 		"Title":   "Dashboard",
 		"Content": "dashboard", // This tells the layout which content template to use
@@ -277,7 +396,7 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	err := s.templates.ExecuteTemplate(w, "layout.html", data)
 	if err != nil {
 		log.Printf("Template execution error: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
 		return
 	}
 }
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+// changeUsername renames the caller. Changes are rate limited by a cooldown,
+// and the old username is held for the previous owner for a while so nobody
+// else can pick it up and impersonate them. The owner may take it back
+// during the hold.
+func (s *Server) changeUsername(w http.ResponseWriter, r *http.Request) {
+	var req ChangeUsernameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	req.Username = s.usernames.Normalize(req.Username)
+	if req.Username == "" {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Username is required")
+		return
+	}
+	if violations := s.usernames.Evaluate(req.Username); len(violations) > 0 {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, violations[0].Message)
+		return
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	if req.Username == user.Username {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "That is already your username")
+		return
+	}
+	if user.UsernameChangedAt.Valid {
+		if next := user.UsernameChangedAt.Time.Add(s.usernameConfig.Cooldown); time.Now().Before(next) {
+			s.writeError(w, r, http.StatusTooManyRequests, problem.CodeRateLimited, "Username was changed recently; try again after "+next.Format(time.RFC1123))
+			return
+		}
+	}
+
+	// The database rejects usernames that are taken, held for someone else
+	// or look like another account's. The old name is held in the same
+	// transaction, so it is never free for others to take in between.
+	// Changing only the letter case keeps the same identity, so there is
+	// nothing to hold then
+	caseOnly := strings.EqualFold(req.Username, user.Username)
+	if err := s.inTx(r.Context(), func(queries *db.Queries) error {
+		if err := queries.ChangeUsername(r.Context(), db.ChangeUsernameParams{
+			ID:               user.ID,
+			Username:         req.Username,
+			UsernameSkeleton: service.UsernameSkeleton(req.Username),
+		}); err != nil {
+			return err
+		}
+		if caseOnly {
+			return nil
+		}
+		if err := queries.ReleaseUsernameHold(r.Context(), req.Username); err != nil {
+			return err
+		}
+		return queries.HoldUsername(r.Context(), db.HoldUsernameParams{
+			Username:  user.Username,
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(s.usernameConfig.HoldPeriod),
+		})
+	}); err != nil {
+		s.writeDBError(w, r, err)
+		return
+	}
+
+	s.audit(r, user.ID, AuditUsernameChanged, map[string]interface{}{
+		"old_username": user.Username,
+		"new_username": req.Username,
+	})
+
+	// Tokens carry the username, so swap the caller's token for one with the
+	// new name
+	oldUsername := user.Username
+	user.Username = req.Username
+	token, err := s.refreshSessionToken(w, r, user)
+	if err != nil {
+		log.Printf("Failed to refresh session token: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":        "Username changed",
+		"username":       user.Username,
+		"old_username":   oldUsername,
+		"token":          token,
+		"held_until":     time.Now().Add(s.usernameConfig.HoldPeriod),
+		"next_change_at": time.Now().Add(s.usernameConfig.Cooldown),
+	})
+}
+
+const usernameConfusableMessage = "Username is too similar to an existing username"
+
+// usernameTaken returns why username cannot be used for a new account: it
+// is taken, held for a previous owner or looks like another account's
+// username. It returns nil if the username is free. Only the live check uses
+// it; writes rely on the database to reject these.
+func (s *Server) usernameTaken(r *http.Request, username string) (*service.PolicyViolation, error) {
+	exists, err := s.db.CheckUsernameExists(r.Context(), username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return &service.PolicyViolation{Rule: "taken", Message: "Username already taken"}, nil
+	}
+
+	lookAlike, err := s.db.CheckUsernameSkeletonExists(r.Context(), db.CheckUsernameSkeletonExistsParams{
+		UsernameSkeleton: service.UsernameSkeleton(username),
+		ID:               uuid.Nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lookAlike {
+		return &service.PolicyViolation{Rule: "confusable", Message: usernameConfusableMessage}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/problem"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// startEmailVerification replaces any pending verification token for user and
+// mails the new one. Delivery happens in the background so a slow SMTP server
+// does not hold up the request; failures are only logged.
+func (s *Server) startEmailVerification(ctx context.Context, user db.User) error {
+	token, hash, err := service.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.SetVerificationToken(ctx, db.SetVerificationTokenParams{
+		ID:                         user.ID,
+		VerificationToken:          sql.NullString{String: hash, Valid: true},
+		VerificationTokenExpiresAt: sql.NullTime{Time: time.Now().Add(s.verificationConfig.TokenTTL), Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.emailService.SendVerificationEmail(user.Email, token); err != nil {
+			log.Printf("Failed to send verification email: %v", err)
+		}
+	}()
+	return nil
+}
+
+// verifyEmail handles the link from the verification email and sends the
+// browser on to the login page with the outcome.
+func (s *Server) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Redirect(w, r, "/login?verification_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	user, err := s.db.GetUserByVerificationToken(r.Context(), sql.NullString{String: service.HashToken(token), Valid: true})
+	if err != nil {
+		http.Redirect(w, r, "/login?verification_failed=true", http.StatusSeeOther)
+		return
+	}
+
+	if err := s.db.MarkEmailVerified(r.Context(), user.ID); err != nil {
+		log.Printf("Failed to mark email verified: %v", err)
+		s.writeError(w, r, http.StatusInternalServerError, problem.CodeInternal, "Internal Server Error")
+		return
+	}
+
+	http.Redirect(w, r, "/login?verified=true", http.StatusSeeOther)
+}
+
+// resendVerification always answers 202 so it cannot be used to find out
+// which addresses are registered.
+func (s *Server) resendVerification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	user, err := s.db.GetUserByEmail(r.Context(), req.Email)
+	if err == nil && !user.EmailVerified {
+		if err := s.startEmailVerification(r.Context(), user); err != nil {
+			log.Printf("Failed to resend verification email: %v", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
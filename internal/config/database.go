@@ -8,9 +8,27 @@ import (
 )
 
 type Config struct {
-	Database DatabaseConfig `mapstructure:"database"`
-	Email    EmailConfig    `mapstructure:"email"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Database          DatabaseConfig          `mapstructure:"database"`
+	Email             EmailConfig             `mapstructure:"email"`
+	JWT               JWTConfig               `mapstructure:"jwt"`
+	PasswordPolicy    PasswordPolicyConfig    `mapstructure:"password_policy"`
+	EmailPolicy       EmailPolicyConfig       `mapstructure:"email_policy"`
+	PasswordPepper    PasswordPepperConfig    `mapstructure:"password_pepper"`
+	EmailVerification EmailVerificationConfig `mapstructure:"email_verification"`
+	EmailChange       EmailChangeConfig       `mapstructure:"email_change"`
+	AccountDeletion   AccountDeletionConfig   `mapstructure:"account_deletion"`
+	DataExport        DataExportConfig        `mapstructure:"data_export"`
+	UsernameChange    UsernameChangeConfig    `mapstructure:"username_change"`
+	UsernamePolicy    UsernamePolicyConfig    `mapstructure:"username_policy"`
+	Storage           StorageConfig           `mapstructure:"storage"`
+	Avatar            AvatarConfig            `mapstructure:"avatar"`
+	MagicLink         MagicLinkConfig         `mapstructure:"magic_link"`
+	EmailOTP          EmailOTPConfig          `mapstructure:"email_otp"`
+	TOTP              TOTPConfig              `mapstructure:"totp"`
+	WebAuthn          WebAuthnConfig          `mapstructure:"webauthn"`
+	SMS               SMSConfig               `mapstructure:"sms"`
+	Reauth            ReauthConfig            `mapstructure:"reauth"`
+	Registration      RegistrationConfig      `mapstructure:"registration"`
 }
 
 type EmailConfig struct {
@@ -19,6 +37,160 @@ type EmailConfig struct {
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	From     string `mapstructure:"from"`
+	BaseURL  string `mapstructure:"base_url"`
+}
+
+// EmailVerificationConfig controls the verification link sent on signup.
+type EmailVerificationConfig struct {
+	RequireForLogin bool          `mapstructure:"require_for_login"`
+	TokenTTL        time.Duration `mapstructure:"token_ttl"`
+}
+
+// EmailChangeConfig sets how long the confirmation link sent to the new
+// address and the revert link sent to the old address stay valid.
+type EmailChangeConfig struct {
+	ConfirmTTL time.Duration `mapstructure:"confirm_ttl"`
+	RevertTTL  time.Duration `mapstructure:"revert_ttl"`
+}
+
+// AccountDeletionConfig sets how long a deleted account can still be
+// restored and how often expired accounts are purged.
+type AccountDeletionConfig struct {
+	GracePeriod   time.Duration `mapstructure:"grace_period"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// UsernameChangeConfig sets how often a user may change their username and
+// how long a released username stays reserved for its previous owner.
+type UsernameChangeConfig struct {
+	Cooldown   time.Duration `mapstructure:"cooldown"`
+	HoldPeriod time.Duration `mapstructure:"hold_period"`
+}
+
+// UsernamePolicyConfig sets which characters a username may contain. By
+// default only ASCII letters and digits are allowed besides
+// AllowedPunctuation; AllowUnicode admits letters from other alphabets.
+type UsernamePolicyConfig struct {
+	MinLength          int    `mapstructure:"min_length"`
+	MaxLength          int    `mapstructure:"max_length"`
+	AllowUnicode       bool   `mapstructure:"allow_unicode"`
+	AllowedPunctuation string `mapstructure:"allowed_punctuation"`
+}
+
+// Registration modes
+const (
+	RegistrationOpen   = "open"
+	RegistrationInvite = "invite"
+	RegistrationClosed = "closed"
+)
+
+// RegistrationConfig decides who may sign up: anyone, only people holding an
+// invite, or nobody. Admins and users whose role is listed in InviteRoles can
+// create invites, which last at most InviteTTL and can be used at most
+// MaxInviteUses times. With RequireApproval set, new accounts cannot sign in
+// until an admin approves them.
+type RegistrationConfig struct {
+	Mode            string        `mapstructure:"mode"`
+	InviteRoles     []string      `mapstructure:"invite_roles"`
+	InviteTTL       time.Duration `mapstructure:"invite_ttl"`
+	MaxInviteUses   int           `mapstructure:"max_invite_uses"`
+	RequireApproval bool          `mapstructure:"require_approval"`
+}
+
+// ReauthConfig sets how recently a user must have proven who they are, by
+// logging in or through /api/reauth, to perform sensitive operations.
+type ReauthConfig struct {
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+// MagicLinkConfig turns passwordless email login on and sets how long a
+// sign-in link stays valid. ResendInterval is the wait between two links to
+// the same user and DailyLimit how many they can be sent in 24 hours.
+type MagicLinkConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	TokenTTL       time.Duration `mapstructure:"token_ttl"`
+	ResendInterval time.Duration `mapstructure:"resend_interval"`
+	DailyLimit     int           `mapstructure:"daily_limit"`
+}
+
+// EmailOTPConfig sets how long an emailed one-time code stays valid and how
+// many wrong guesses it tolerates before it is burned. ResendInterval is the
+// wait between two codes to the same user and DailyLimit how many they can
+// be sent in 24 hours.
+type EmailOTPConfig struct {
+	CodeTTL        time.Duration `mapstructure:"code_ttl"`
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	ResendInterval time.Duration `mapstructure:"resend_interval"`
+	DailyLimit     int           `mapstructure:"daily_limit"`
+}
+
+// TOTPConfig names the issuer shown in authenticator apps and where the key
+// that encrypts TOTP secrets at rest comes from: KeyFile, or the environment
+// variable KeyEnv when KeyFile is empty. MaxAttempts is how many wrong second
+// factor codes in a row lock the account.
+type TOTPConfig struct {
+	Issuer      string `mapstructure:"issuer"`
+	KeyFile     string `mapstructure:"key_file"`
+	KeyEnv      string `mapstructure:"key_env"`
+	MaxAttempts int    `mapstructure:"max_attempts"`
+}
+
+// WebAuthnConfig identifies the site to passkey authenticators. RPID is the
+// domain passkeys are bound to, RPOrigins the origins browsers may report,
+// and ChallengeTTL how long a registration or sign-in ceremony may take.
+type WebAuthnConfig struct {
+	RPID          string        `mapstructure:"rp_id"`
+	RPDisplayName string        `mapstructure:"rp_display_name"`
+	RPOrigins     []string      `mapstructure:"rp_origins"`
+	ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`
+}
+
+// SMSConfig picks how text messages and voice calls are delivered and sets
+// how long a phone code stays valid and how many wrong guesses it tolerates.
+// Provider is "http" to post messages to a gateway, or "file" to append them
+// to File, or to the log when File is empty, for development and tests.
+// ResendInterval is the wait between two codes to the same user and
+// DailyLimit how many they can be sent in 24 hours.
+type SMSConfig struct {
+	Provider       string        `mapstructure:"provider"`
+	From           string        `mapstructure:"from"`
+	File           string        `mapstructure:"file"`
+	HTTP           SMSHTTPConfig `mapstructure:"http"`
+	CodeTTL        time.Duration `mapstructure:"code_ttl"`
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	ResendInterval time.Duration `mapstructure:"resend_interval"`
+	DailyLimit     int           `mapstructure:"daily_limit"`
+}
+
+// SMSHTTPConfig points the generic HTTP provider at a gateway. The API key
+// is read from the environment variable APIKeyEnv.
+type SMSHTTPConfig struct {
+	URL       string        `mapstructure:"url"`
+	APIKeyEnv string        `mapstructure:"api_key_env"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// StorageConfig sets where uploaded files are kept.
+type StorageConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// AvatarConfig limits avatar uploads and sets the square thumbnail sizes,
+// in pixels, generated from them.
+type AvatarConfig struct {
+	MaxBytes  int64 `mapstructure:"max_bytes"`
+	MaxPixels int   `mapstructure:"max_pixels"`
+	Sizes     []int `mapstructure:"sizes"`
+}
+
+// DataExportConfig controls personal data exports. Exports with more than
+// AsyncThreshold records are written to Dir in the background and offered as
+// a download link valid for LinkTTL.
+type DataExportConfig struct {
+	Dir             string        `mapstructure:"dir"`
+	AsyncThreshold  int           `mapstructure:"async_threshold"`
+	LinkTTL         time.Duration `mapstructure:"link_ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
 }
 
 type JWTConfig struct {
@@ -26,6 +198,56 @@ type JWTConfig struct {
 	TokenDuration time.Duration `mapstructure:"token_duration"`
 }
 
+// PasswordPolicyConfig describes the rules a password must satisfy. Version
+// should be bumped whenever the rules are tightened so stored passwords can be
+// traced back to the policy they were last checked against.
+type PasswordPolicyConfig struct {
+	Version         int           `mapstructure:"version"`
+	MinLength       int           `mapstructure:"min_length"`
+	MaxLength       int           `mapstructure:"max_length"`
+	RequireUpper    bool          `mapstructure:"require_upper"`
+	RequireLower    bool          `mapstructure:"require_lower"`
+	RequireDigit    bool          `mapstructure:"require_digit"`
+	RequireSpecial  bool          `mapstructure:"require_special"`
+	BannedWordsFile string        `mapstructure:"banned_words_file"`
+	AllowUserInfo   bool          `mapstructure:"allow_user_info"`
+	MinStrength     int           `mapstructure:"min_strength"`
+	HistorySize     int           `mapstructure:"history_size"`
+	MaxAge          time.Duration `mapstructure:"max_age"`
+}
+
+// EmailPolicyConfig limits which addresses can be used for an account. When
+// AllowedDomains is set only those domains and their subdomains are
+// accepted. DisposableDomainsFile replaces the bundled list of throwaway
+// email services, and CheckMX rejects domains that publish no mail servers.
+type EmailPolicyConfig struct {
+	AllowedDomains        []string      `mapstructure:"allowed_domains"`
+	BlockedDomains        []string      `mapstructure:"blocked_domains"`
+	BlockDisposable       bool          `mapstructure:"block_disposable"`
+	DisposableDomainsFile string        `mapstructure:"disposable_domains_file"`
+	CheckMX               bool          `mapstructure:"check_mx"`
+	MXTimeout             time.Duration `mapstructure:"mx_timeout"`
+}
+
+// PasswordPepperConfig lists the server-side pepper keys. CurrentVersion is
+// used for new hashes; keep retired versions listed until every hash made with
+// them has been re-peppered. Password history is never re-peppered, so a
+// retired key must also stay until the history entries made with it have
+// been pruned; password changes fail while one of them cannot be checked. A
+// CurrentVersion of 0 disables the pepper.
+type PasswordPepperConfig struct {
+	CurrentVersion int               `mapstructure:"current_version"`
+	Keys           []PepperKeyConfig `mapstructure:"keys"`
+}
+
+// PepperKeyConfig loads a key from File, or from the environment variable Env
+// when File is empty.
+type PepperKeyConfig struct {
+	Version int    `mapstructure:"version"`
+	File    string `mapstructure:"file"`
+	Env     string `mapstructure:"env"`
+}
+
 type DatabaseConfig struct {
 	Host            string        `mapstructure:"host"`
 	Port            int           `mapstructure:"port"`
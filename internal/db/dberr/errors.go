@@ -0,0 +1,96 @@
+// Package dberr turns PostgreSQL errors into errors the rest of the
+// application can act on without knowing SQLSTATE codes or constraint names.
+package dberr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Domain errors. Match them with errors.Is on the result of Translate.
+var (
+	// ErrConflict matches every unique violation, including the ones below
+	// that say which identity was taken.
+	ErrConflict           = errors.New("conflicts with an existing record")
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrUsernameTaken      = errors.New("username already taken")
+	ErrUsernameConfusable = errors.New("username is too similar to an existing username")
+
+	ErrReferenceNotFound = errors.New("referenced record does not exist")
+
+	// ErrConcurrentUpdate means the transaction lost a race with another
+	// one and can be retried.
+	ErrConcurrentUpdate = errors.New("conflicted with a concurrent update")
+)
+
+// PostgreSQL error codes handled here
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// identityConstraints maps the unique constraints that guard identities, and
+// the names the users_check_identities and user_identifiers_check_email
+// triggers report, to their meaning.
+var identityConstraints = map[string]error{
+	"users_email_key":                     ErrEmailTaken,
+	"users_email_reserved":                ErrEmailTaken,
+	"idx_user_identifiers_verified_email": ErrEmailTaken,
+	"user_identifiers_email_in_use":       ErrEmailTaken,
+	"users_username_key":                  ErrUsernameTaken,
+	"users_username_reserved":             ErrUsernameTaken,
+	"username_holds_pkey":                 ErrUsernameTaken,
+	"users_username_confusable":           ErrUsernameConfusable,
+}
+
+// ConstraintError is a constraint violation reported by the database. It
+// matches its domain errors with errors.Is and the underlying *pq.Error with
+// errors.As.
+type ConstraintError struct {
+	Constraint string
+	Kind       error
+	Err        *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%v (constraint %s)", e.Kind, e.Constraint)
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	errs := []error{e.Kind}
+	if e.Err.Code == codeUniqueViolation && e.Kind != ErrConflict {
+		errs = append(errs, ErrConflict)
+	}
+	return append(errs, e.Err)
+}
+
+// Translate returns the domain error for err if it is a database error this
+// package knows about, and err unchanged otherwise. Unique violations match
+// ErrConflict and, for identity constraints, ErrEmailTaken, ErrUsernameTaken
+// or ErrUsernameConfusable. Foreign key violations match
+// ErrReferenceNotFound, and serialization failures and deadlocks match
+// ErrConcurrentUpdate.
+func Translate(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case codeUniqueViolation:
+		kind, ok := identityConstraints[pqErr.Constraint]
+		if !ok {
+			kind = ErrConflict
+		}
+		return &ConstraintError{Constraint: pqErr.Constraint, Kind: kind, Err: pqErr}
+	case codeForeignKeyViolation:
+		return &ConstraintError{Constraint: pqErr.Constraint, Kind: ErrReferenceNotFound, Err: pqErr}
+	case codeSerializationFailure, codeDeadlockDetected:
+		return fmt.Errorf("%w: %w", ErrConcurrentUpdate, pqErr)
+	}
+	return err
+}
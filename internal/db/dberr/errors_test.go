@@ -0,0 +1,151 @@
+package dberr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestTranslate(t *testing.T) {
+	plain := errors.New("connection refused")
+
+	tests := []struct {
+		name  string
+		err   error
+		is    []error
+		isNot []error
+	}{
+		{
+			name: "users email key",
+			err:  &pq.Error{Code: "23505", Constraint: "users_email_key"},
+			is:   []error{ErrEmailTaken, ErrConflict},
+		},
+		{
+			name: "email confirmed on another account",
+			err:  &pq.Error{Code: "23505", Constraint: "users_email_reserved"},
+			is:   []error{ErrEmailTaken, ErrConflict},
+		},
+		{
+			name: "additional email already confirmed",
+			err:  &pq.Error{Code: "23505", Constraint: "idx_user_identifiers_verified_email"},
+			is:   []error{ErrEmailTaken, ErrConflict},
+		},
+		{
+			name: "additional email is another account's primary",
+			err:  &pq.Error{Code: "23505", Constraint: "user_identifiers_email_in_use"},
+			is:   []error{ErrEmailTaken, ErrConflict},
+		},
+		{
+			name:  "users username key",
+			err:   &pq.Error{Code: "23505", Constraint: "users_username_key"},
+			is:    []error{ErrUsernameTaken, ErrConflict},
+			isNot: []error{ErrEmailTaken},
+		},
+		{
+			name: "username held for its previous owner",
+			err:  &pq.Error{Code: "23505", Constraint: "users_username_reserved"},
+			is:   []error{ErrUsernameTaken, ErrConflict},
+		},
+		{
+			name: "username hold key",
+			err:  &pq.Error{Code: "23505", Constraint: "username_holds_pkey"},
+			is:   []error{ErrUsernameTaken, ErrConflict},
+		},
+		{
+			name:  "look-alike username",
+			err:   &pq.Error{Code: "23505", Constraint: "users_username_confusable"},
+			is:    []error{ErrUsernameConfusable, ErrConflict},
+			isNot: []error{ErrUsernameTaken},
+		},
+		{
+			name:  "other unique violation",
+			err:   &pq.Error{Code: "23505", Constraint: "invites_code_hash_key"},
+			is:    []error{ErrConflict},
+			isNot: []error{ErrEmailTaken, ErrUsernameTaken, ErrUsernameConfusable},
+		},
+		{
+			name:  "foreign key violation",
+			err:   &pq.Error{Code: "23503", Constraint: "sessions_user_id_fkey"},
+			is:    []error{ErrReferenceNotFound},
+			isNot: []error{ErrConflict},
+		},
+		{
+			name: "serialization failure",
+			err:  &pq.Error{Code: "40001"},
+			is:   []error{ErrConcurrentUpdate},
+		},
+		{
+			name: "deadlock",
+			err:  &pq.Error{Code: "40P01"},
+			is:   []error{ErrConcurrentUpdate},
+		},
+		{
+			name: "wrapped database error",
+			err:  fmt.Errorf("failed to create user: %w", &pq.Error{Code: "23505", Constraint: "users_email_key"}),
+			is:   []error{ErrEmailTaken},
+		},
+		{
+			name:  "unhandled database error",
+			err:   &pq.Error{Code: "23502", Column: "email"},
+			isNot: []error{ErrConflict, ErrReferenceNotFound, ErrConcurrentUpdate},
+		},
+		{
+			name:  "not a database error",
+			err:   plain,
+			is:    []error{plain},
+			isNot: []error{ErrConflict, ErrConcurrentUpdate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Translate(tt.err)
+			for _, target := range tt.is {
+				if !errors.Is(got, target) {
+					t.Errorf("Translate(%v) = %v, want it to match %v", tt.err, got, target)
+				}
+			}
+			for _, target := range tt.isNot {
+				if errors.Is(got, target) {
+					t.Errorf("Translate(%v) = %v, want it not to match %v", tt.err, got, target)
+				}
+			}
+
+			var pqErr *pq.Error
+			if errors.As(tt.err, &pqErr) && !errors.As(got, &pqErr) {
+				t.Errorf("Translate(%v) = %v, lost the *pq.Error", tt.err, got)
+			}
+		})
+	}
+}
+
+// TestTriggerConstraintsAreMapped checks that every constraint name a
+// migration raises by hand is known to Translate, so a typo on either side
+// cannot turn a conflict back into an internal error.
+func TestTriggerConstraintsAreMapped(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations found")
+	}
+
+	raised := regexp.MustCompile(`CONSTRAINT = '(\w+)'`)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range raised.FindAllStringSubmatch(string(data), -1) {
+			if _, ok := identityConstraints[match[1]]; !ok {
+				t.Errorf("%s raises constraint %q, which Translate does not map", filepath.Base(file), match[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN role;
\ No newline at end of file
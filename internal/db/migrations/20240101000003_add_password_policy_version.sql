@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN password_policy_version INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN password_policy_version;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE password_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_history_user_id ON password_history(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_history;
\ No newline at end of file
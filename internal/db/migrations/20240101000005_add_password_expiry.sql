@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN password_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN password_changed_at,
+DROP COLUMN must_change_password;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN verification_token_expires_at TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN verification_token_expires_at;
\ No newline at end of file
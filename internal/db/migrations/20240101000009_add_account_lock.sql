@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN locked_at TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN locked_at;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    event_type VARCHAR(64) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_user_id ON audit_events(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE email_change_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    old_email_verified BOOLEAN NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    confirm_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    confirm_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    revert_token_hash VARCHAR(64) UNIQUE,
+    revert_expires_at TIMESTAMP WITH TIME ZONE,
+    reverted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_change_requests_user_id ON email_change_requests(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS email_change_requests;
\ No newline at end of file
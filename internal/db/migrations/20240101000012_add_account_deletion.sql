@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN deletion_requested_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN deletion_restore_token_hash VARCHAR(64);
+
+CREATE INDEX idx_users_deletion_requested_at ON users(deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_deletion_requested_at;
+
+ALTER TABLE users
+DROP COLUMN deletion_requested_at,
+DROP COLUMN deletion_restore_token_hash;
\ No newline at end of file
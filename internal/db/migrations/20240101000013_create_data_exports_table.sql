@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    file_path TEXT,
+    download_token_hash VARCHAR(64) UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS data_exports;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN username_changed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE username_holds (
+    username VARCHAR(50) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_username_holds_user_id ON username_holds(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS username_holds;
+
+ALTER TABLE users
+DROP COLUMN username_changed_at;
\ No newline at end of file
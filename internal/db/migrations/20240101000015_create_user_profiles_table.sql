@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE user_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    bio VARCHAR(500) NOT NULL DEFAULT '',
+    locale VARCHAR(35) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    avatar_key VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_profiles;
\ No newline at end of file
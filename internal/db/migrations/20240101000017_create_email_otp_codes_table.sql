@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE email_otp_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_otp_codes_user_id_purpose ON email_otp_codes(user_id, purpose);
+
+ALTER TABLE users
+ADD COLUMN email_otp_mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN email_otp_mfa_enabled;
+
+DROP TABLE IF EXISTS email_otp_codes;
\ No newline at end of file
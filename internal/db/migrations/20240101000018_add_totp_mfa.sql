@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN totp_secret_encrypted TEXT,
+ADD COLUMN totp_enabled_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN totp_last_used_step BIGINT;
+
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+ALTER TABLE users
+DROP COLUMN totp_last_used_step,
+DROP COLUMN totp_enabled_at,
+DROP COLUMN totp_secret_encrypted;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE email_otp_codes RENAME TO otp_codes;
+ALTER INDEX idx_email_otp_codes_user_id_purpose RENAME TO idx_otp_codes_user_id_purpose;
+
+ALTER TABLE users
+ADD COLUMN phone_number VARCHAR(20),
+ADD COLUMN phone_verified_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN phone_mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN phone_mfa_enabled,
+DROP COLUMN phone_verified_at,
+DROP COLUMN phone_number;
+
+ALTER INDEX idx_otp_codes_user_id_purpose RENAME TO idx_email_otp_codes_user_id_purpose;
+ALTER TABLE otp_codes RENAME TO email_otp_codes;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Additional email addresses an account can sign in with. The primary
+-- address stays in users.email, which is where mail is sent. Until it is
+-- verified an address does not belong to anyone, so several accounts may
+-- have it pending.
+CREATE TABLE user_identifiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    verification_token_hash VARCHAR(64),
+    verification_expires_at TIMESTAMP WITH TIME ZONE,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_identifiers_user_id ON user_identifiers(user_id);
+CREATE UNIQUE INDEX idx_user_identifiers_verified_email ON user_identifiers(email) WHERE verified_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS user_identifiers;
\ No newline at end of file
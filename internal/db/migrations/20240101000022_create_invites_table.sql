@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    inviter_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    email VARCHAR(255),
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_invites_inviter_id ON invites(inviter_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS invites;
\ No newline at end of file
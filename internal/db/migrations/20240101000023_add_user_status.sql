@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active'
+CHECK (status IN ('pending_approval', 'active', 'rejected', 'suspended'));
+
+CREATE INDEX idx_users_pending_approval ON users(created_at) WHERE status = 'pending_approval';
+
+-- +goose Down
+DROP INDEX idx_users_pending_approval;
+
+ALTER TABLE users
+DROP COLUMN status;
\ No newline at end of file
@@ -0,0 +1,61 @@
+-- +goose Up
+-- Refuse to migrate while accounts differ only by letter case, since the
+-- case-insensitive unique constraints below could not be built. Merge or
+-- rename the listed accounts first.
+-- +goose StatementBegin
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(kind || ' "' || value || '"', ', ' ORDER BY kind, value) INTO duplicates
+    FROM (
+        SELECT 'email' AS kind, LOWER(email) AS value FROM users
+        GROUP BY LOWER(email) HAVING COUNT(*) > 1
+        UNION ALL
+        SELECT 'username', LOWER(username) FROM users
+        GROUP BY LOWER(username) HAVING COUNT(*) > 1
+        UNION ALL
+        SELECT 'additional email', LOWER(email) FROM user_identifiers
+        WHERE verified_at IS NOT NULL
+        GROUP BY LOWER(email) HAVING COUNT(*) > 1
+        UNION ALL
+        SELECT 'held username', LOWER(username) FROM username_holds
+        GROUP BY LOWER(username) HAVING COUNT(*) > 1
+    ) AS found;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'Found identities that differ only by letter case: %', duplicates
+            USING HINT = 'Merge or rename these accounts, then run the migration again';
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+CREATE EXTENSION IF NOT EXISTS citext;
+
+ALTER TABLE users
+ALTER COLUMN email TYPE CITEXT,
+ALTER COLUMN username TYPE CITEXT,
+ADD COLUMN username_skeleton TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_users_username_skeleton ON users(username_skeleton);
+
+ALTER TABLE user_identifiers
+ALTER COLUMN email TYPE CITEXT;
+
+ALTER TABLE username_holds
+ALTER COLUMN username TYPE CITEXT;
+
+-- +goose Down
+ALTER TABLE username_holds
+ALTER COLUMN username TYPE VARCHAR(50);
+
+ALTER TABLE user_identifiers
+ALTER COLUMN email TYPE VARCHAR(255);
+
+DROP INDEX idx_users_username_skeleton;
+
+ALTER TABLE users
+DROP COLUMN username_skeleton,
+ALTER COLUMN username TYPE VARCHAR(50),
+ALTER COLUMN email TYPE VARCHAR(255);
\ No newline at end of file
@@ -0,0 +1,53 @@
+-- +goose Up
+-- The unique constraints on users only cover users itself. This trigger
+-- extends them to addresses confirmed on another account, usernames held for
+-- their previous owner and usernames that look like another account's, so
+-- registration and renames can rely on the insert or update failing instead
+-- of checking first. Violations are reported as unique violations with
+-- their own constraint names.
+-- +goose StatementBegin
+CREATE FUNCTION users_check_identities() RETURNS trigger AS $$
+BEGIN
+    -- Only identities being set are checked, so accounts that already
+    -- collide can still be updated
+    IF (TG_OP = 'INSERT' OR NEW.email IS DISTINCT FROM OLD.email) AND EXISTS (
+        SELECT 1 FROM user_identifiers
+        WHERE email = NEW.email AND verified_at IS NOT NULL AND user_id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'email address is in use by another account'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_email_reserved', TABLE = 'users';
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND NEW.username IS NOT DISTINCT FROM OLD.username THEN
+        RETURN NEW;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM username_holds
+        WHERE username = NEW.username AND expires_at > NOW() AND user_id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'username is held for its previous owner'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_username_reserved', TABLE = 'users';
+    END IF;
+
+    IF NEW.username_skeleton <> '' AND EXISTS (
+        SELECT 1 FROM users
+        WHERE username_skeleton = NEW.username_skeleton AND id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'username looks like the username of another account'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_username_confusable', TABLE = 'users';
+    END IF;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER users_check_identities
+BEFORE INSERT OR UPDATE OF email, username ON users
+FOR EACH ROW EXECUTE FUNCTION users_check_identities();
+
+-- +goose Down
+DROP TRIGGER users_check_identities ON users;
+
+DROP FUNCTION users_check_identities();
\ No newline at end of file
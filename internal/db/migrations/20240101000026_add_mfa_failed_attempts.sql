@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN mfa_failed_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN mfa_failed_attempts;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The way skeletons are computed changed. Clear them so the backfill at
+-- startup recomputes every one.
+UPDATE users SET username_skeleton = '';
+
+-- +goose Down
+UPDATE users SET username_skeleton = '';
\ No newline at end of file
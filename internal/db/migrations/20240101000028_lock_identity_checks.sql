@@ -0,0 +1,132 @@
+-- +goose Up
+-- The checks in users_check_identities read other rows, which under READ
+-- COMMITTED cannot see a concurrent transaction that has not committed yet,
+-- so two sign-ups or renames could both pass them. Each identity being set
+-- now takes a transaction-level advisory lock first. A second transaction
+-- setting the same identity waits until the first ends, and the check that
+-- follows runs with a fresh snapshot that includes its rows. Usernames are
+-- locked before and after a rename, since the hold on the old name is
+-- written in the same transaction. Identities are locked in a fixed order:
+-- email, usernames, then skeleton.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION users_check_identities() RETURNS trigger AS $$
+BEGIN
+    -- Only identities being set are checked, so accounts that already
+    -- collide can still be updated
+    IF TG_OP = 'INSERT' OR NEW.email IS DISTINCT FROM OLD.email THEN
+        PERFORM pg_advisory_xact_lock(hashtext('email:' || LOWER(NEW.email)));
+
+        IF EXISTS (
+            SELECT 1 FROM user_identifiers
+            WHERE email = NEW.email AND verified_at IS NOT NULL AND user_id <> NEW.id
+        ) THEN
+            RAISE EXCEPTION 'email address is in use by another account'
+                USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_email_reserved', TABLE = 'users';
+        END IF;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND NEW.username IS NOT DISTINCT FROM OLD.username THEN
+        RETURN NEW;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND LOWER(OLD.username) < LOWER(NEW.username) THEN
+        PERFORM pg_advisory_xact_lock(hashtext('username:' || LOWER(OLD.username)));
+    END IF;
+    PERFORM pg_advisory_xact_lock(hashtext('username:' || LOWER(NEW.username)));
+    IF TG_OP = 'UPDATE' AND LOWER(OLD.username) > LOWER(NEW.username) THEN
+        PERFORM pg_advisory_xact_lock(hashtext('username:' || LOWER(OLD.username)));
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM username_holds
+        WHERE username = NEW.username AND expires_at > NOW() AND user_id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'username is held for its previous owner'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_username_reserved', TABLE = 'users';
+    END IF;
+
+    IF NEW.username_skeleton <> '' THEN
+        PERFORM pg_advisory_xact_lock(hashtext('username_skeleton:' || NEW.username_skeleton));
+
+        IF EXISTS (
+            SELECT 1 FROM users
+            WHERE username_skeleton = NEW.username_skeleton AND id <> NEW.id
+        ) THEN
+            RAISE EXCEPTION 'username looks like the username of another account'
+                USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_username_confusable', TABLE = 'users';
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Confirming an additional address takes the same email lock, so it cannot
+-- race a sign-up or email change to that address on another account.
+-- +goose StatementBegin
+CREATE FUNCTION user_identifiers_check_email() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('email:' || LOWER(NEW.email)));
+
+    IF EXISTS (
+        SELECT 1 FROM users
+        WHERE email = NEW.email AND id <> NEW.user_id
+    ) THEN
+        RAISE EXCEPTION 'email address is in use by another account'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'user_identifiers_email_in_use', TABLE = 'user_identifiers';
+    END IF;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER user_identifiers_check_email
+BEFORE INSERT OR UPDATE OF email, verified_at ON user_identifiers
+FOR EACH ROW
+WHEN (NEW.verified_at IS NOT NULL)
+EXECUTE FUNCTION user_identifiers_check_email();
+
+-- +goose Down
+DROP TRIGGER user_identifiers_check_email ON user_identifiers;
+
+DROP FUNCTION user_identifiers_check_email();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION users_check_identities() RETURNS trigger AS $$
+BEGIN
+    -- Only identities being set are checked, so accounts that already
+    -- collide can still be updated
+    IF (TG_OP = 'INSERT' OR NEW.email IS DISTINCT FROM OLD.email) AND EXISTS (
+        SELECT 1 FROM user_identifiers
+        WHERE email = NEW.email AND verified_at IS NOT NULL AND user_id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'email address is in use by another account'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_email_reserved', TABLE = 'users';
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND NEW.username IS NOT DISTINCT FROM OLD.username THEN
+        RETURN NEW;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM username_holds
+        WHERE username = NEW.username AND expires_at > NOW() AND user_id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'username is held for its previous owner'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_username_reserved', TABLE = 'users';
+    END IF;
+
+    IF NEW.username_skeleton <> '' AND EXISTS (
+        SELECT 1 FROM users
+        WHERE username_skeleton = NEW.username_skeleton AND id <> NEW.id
+    ) THEN
+        RAISE EXCEPTION 'username looks like the username of another account'
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'users_username_confusable', TABLE = 'users';
+    END IF;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    user_id,
+    event_type,
+    ip_address,
+    user_agent,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: AnonymizeAuditEvents :exec
+UPDATE audit_events
+SET user_id = NULL, ip_address = '', user_agent = '', metadata = '{}'
+WHERE user_id = $1;
+
+-- name: ListUserAuditEvents :many
+SELECT * FROM audit_events
+WHERE user_id = $1
+ORDER BY created_at DESC;
\ No newline at end of file
@@ -0,0 +1,51 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (
+    user_id
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready',
+    file_path = $2,
+    download_token_hash = $3,
+    expires_at = $4,
+    completed_at = NOW()
+WHERE id = $1;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', completed_at = NOW()
+WHERE id = $1;
+
+-- name: GetDataExport :one
+SELECT * FROM data_exports
+WHERE id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: GetDataExportByToken :one
+SELECT * FROM data_exports
+WHERE download_token_hash = $1
+AND user_id = $2
+AND status = 'ready'
+AND expires_at > NOW()
+LIMIT 1;
+
+-- name: ListExpiredDataExports :many
+SELECT * FROM data_exports
+WHERE status = 'ready' AND expires_at < NOW();
+
+-- name: ListUserDataExportFiles :many
+SELECT file_path FROM data_exports
+WHERE user_id = $1 AND file_path IS NOT NULL;
+
+-- name: DeleteDataExport :exec
+DELETE FROM data_exports
+WHERE id = $1;
+
+-- name: CountUserExportRecords :one
+SELECT (
+    (SELECT COUNT(*) FROM sessions WHERE sessions.user_id = $1) +
+    (SELECT COUNT(*) FROM audit_events WHERE audit_events.user_id = $1)
+)::BIGINT AS total;
\ No newline at end of file
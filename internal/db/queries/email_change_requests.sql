@@ -0,0 +1,44 @@
+-- name: CreateEmailChangeRequest :one
+INSERT INTO email_change_requests (
+    user_id,
+    old_email,
+    old_email_verified,
+    new_email,
+    confirm_token_hash,
+    confirm_expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: CancelPendingEmailChanges :exec
+UPDATE email_change_requests
+SET confirm_expires_at = NOW()
+WHERE user_id = $1
+AND confirmed_at IS NULL
+AND confirm_expires_at > NOW();
+
+-- name: ConfirmEmailChange :one
+UPDATE email_change_requests
+SET confirmed_at = NOW()
+WHERE confirm_token_hash = $1
+AND confirmed_at IS NULL
+AND confirm_expires_at > NOW()
+RETURNING *;
+
+-- name: SetEmailChangeRevertToken :exec
+UPDATE email_change_requests
+SET revert_token_hash = $2, revert_expires_at = $3
+WHERE id = $1;
+
+-- name: RevertEmailChange :one
+UPDATE email_change_requests
+SET reverted_at = NOW()
+WHERE revert_token_hash = $1
+AND reverted_at IS NULL
+AND revert_expires_at > NOW()
+RETURNING *;
+
+-- name: ListUserEmailChangeRequests :many
+SELECT id, old_email, new_email, created_at, confirmed_at, reverted_at FROM email_change_requests
+WHERE user_id = $1
+ORDER BY created_at DESC;
\ No newline at end of file
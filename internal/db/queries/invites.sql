@@ -0,0 +1,44 @@
+-- name: CreateInvite :one
+INSERT INTO invites (
+    code_hash,
+    inviter_id,
+    email,
+    max_uses,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetInvite :one
+SELECT * FROM invites
+WHERE id = $1;
+
+-- name: ListInvitesByInviter :many
+SELECT * FROM invites
+WHERE inviter_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListInvites :many
+SELECT * FROM invites
+ORDER BY created_at DESC;
+
+-- name: GetUsableInvite :one
+SELECT * FROM invites
+WHERE code_hash = $1
+AND revoked_at IS NULL
+AND expires_at > NOW()
+AND uses < max_uses;
+
+-- name: RedeemInvite :one
+UPDATE invites
+SET uses = uses + 1
+WHERE code_hash = $1
+AND revoked_at IS NULL
+AND expires_at > NOW()
+AND uses < max_uses
+RETURNING *;
+
+-- name: RevokeInvite :exec
+UPDATE invites
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL;
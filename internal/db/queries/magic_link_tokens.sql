@@ -0,0 +1,28 @@
+-- name: CreateMagicLinkToken :exec
+INSERT INTO magic_link_tokens (
+    user_id,
+    token_hash,
+    nonce_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: ConsumeMagicLinkToken :one
+UPDATE magic_link_tokens
+SET used_at = NOW()
+WHERE token_hash = $1
+AND nonce_hash = $2
+AND used_at IS NULL
+AND expires_at > NOW()
+RETURNING user_id;
+
+-- name: CountMagicLinkTokensSince :one
+SELECT COUNT(*) FROM magic_link_tokens
+WHERE user_id = sqlc.arg(user_id)
+AND created_at > sqlc.arg(since);
+
+-- name: InvalidateMagicLinkTokens :exec
+UPDATE magic_link_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (
+    user_id,
+    code_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM mfa_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
\ No newline at end of file
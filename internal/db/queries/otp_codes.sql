@@ -0,0 +1,45 @@
+-- name: CreateOTP :exec
+INSERT INTO otp_codes (
+    user_id,
+    purpose,
+    code_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: GetActiveOTP :one
+SELECT * FROM otp_codes
+WHERE user_id = $1
+AND purpose = $2
+AND used_at IS NULL
+AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: IncrementOTPAttempts :one
+UPDATE otp_codes
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING attempts;
+
+-- name: UseOTP :execrows
+UPDATE otp_codes
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: InvalidateOTPs :exec
+UPDATE otp_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
+
+-- name: InvalidateUserOTPs :exec
+UPDATE otp_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: CountOTPsSince :one
+SELECT COUNT(*) FROM otp_codes
+WHERE user_id = sqlc.arg(user_id)
+AND purpose = ANY(sqlc.arg(purposes)::TEXT[])
+AND created_at > sqlc.arg(since);
\ No newline at end of file
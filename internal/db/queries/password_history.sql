@@ -0,0 +1,23 @@
+-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (
+    user_id,
+    password_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: ListPasswordHistory :many
+SELECT password_hash FROM password_history
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: PrunePasswordHistory :exec
+DELETE FROM password_history
+WHERE password_history.user_id = $1
+AND id NOT IN (
+    SELECT h.id FROM password_history h
+    WHERE h.user_id = $1
+    ORDER BY h.created_at DESC, h.id DESC
+    LIMIT $2
+);
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: GetPasswordResetToken :one
+SELECT * FROM password_reset_tokens
+WHERE token_hash = $1
+AND used_at IS NULL
+AND expires_at > NOW()
+LIMIT 1;
+
+-- name: MarkPasswordResetTokensUsed :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE token_hash = $1
+AND used_at IS NULL
+AND expires_at > NOW()
+RETURNING user_id;
+
+-- name: ListUserPasswordResetTokens :many
+SELECT id, created_at, expires_at, used_at FROM password_reset_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
\ No newline at end of file
@@ -0,0 +1,37 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+    user_id,
+    user_agent,
+    ip_address,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: IsSessionActive :one
+SELECT EXISTS(
+    SELECT 1 FROM sessions
+    WHERE id = $1
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+) AS active;
+
+-- name: RevokeSession :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeOtherUserSessions :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL;
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE user_id = $1
+ORDER BY created_at DESC;
\ No newline at end of file
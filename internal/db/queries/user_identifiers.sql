@@ -0,0 +1,36 @@
+-- name: CreateUserIdentifier :one
+INSERT INTO user_identifiers (
+    user_id,
+    email,
+    verification_token_hash,
+    verification_expires_at,
+    verified_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListUserIdentifiers :many
+SELECT * FROM user_identifiers
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetUserIdentifier :one
+SELECT * FROM user_identifiers
+WHERE id = $1 AND user_id = $2;
+
+-- name: VerifyUserIdentifier :one
+UPDATE user_identifiers
+SET verified_at = NOW(), verification_token_hash = NULL, verification_expires_at = NULL
+WHERE verification_token_hash = $1
+AND verified_at IS NULL
+AND verification_expires_at > NOW()
+AND NOT EXISTS (SELECT 1 FROM users WHERE users.email = user_identifiers.email)
+RETURNING *;
+
+-- name: GetUserIDByVerifiedIdentifier :one
+SELECT user_id FROM user_identifiers
+WHERE email = $1 AND verified_at IS NOT NULL;
+
+-- name: DeleteUserIdentifier :execrows
+DELETE FROM user_identifiers
+WHERE id = $1 AND user_id = $2;
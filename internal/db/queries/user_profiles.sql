@@ -0,0 +1,32 @@
+-- name: GetUserProfile :one
+SELECT * FROM user_profiles
+WHERE user_id = $1 LIMIT 1;
+
+-- name: UpsertUserProfile :one
+INSERT INTO user_profiles (
+    user_id,
+    display_name,
+    bio,
+    locale,
+    timezone
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    bio = EXCLUDED.bio,
+    locale = EXCLUDED.locale,
+    timezone = EXCLUDED.timezone,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: SetUserAvatar :exec
+INSERT INTO user_profiles (
+    user_id,
+    avatar_key
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET avatar_key = EXCLUDED.avatar_key,
+    updated_at = NOW();
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- name: HoldUsername :exec
+INSERT INTO username_holds (
+    username,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (username) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    expires_at = EXCLUDED.expires_at,
+    created_at = NOW();
+
+-- name: GetUsernameHoldOwner :one
+SELECT user_id FROM username_holds
+WHERE username = $1 AND expires_at > NOW()
+LIMIT 1;
+
+-- name: ListUserUsernameHolds :many
+SELECT username, expires_at, created_at FROM username_holds
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ReleaseUsernameHold :exec
+DELETE FROM username_holds
+WHERE username = $1;
\ No newline at end of file
@@ -2,11 +2,18 @@
 INSERT INTO users (
     email,
     username,
-    password_hash
+    password_hash,
+    password_policy_version,
+    status,
+    username_skeleton
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1 LIMIT 1;
+
 -- name: GetUserByEmail :one
 SELECT * FROM users
 WHERE email = $1 LIMIT 1;
@@ -18,9 +25,179 @@ WHERE username = $1 LIMIT 1;
 -- name: CheckEmailExists :one
 SELECT EXISTS(
     SELECT 1 FROM users WHERE email = $1
+    UNION ALL
+    SELECT 1 FROM user_identifiers WHERE email = $1 AND verified_at IS NOT NULL
 ) AS exists;
 
 -- name: CheckUsernameExists :one
+SELECT (
+    EXISTS(SELECT 1 FROM users WHERE users.username = $1) OR
+    EXISTS(SELECT 1 FROM username_holds WHERE username_holds.username = $1 AND expires_at > NOW())
+)::BOOLEAN AS exists;
+
+-- name: UpdatePasswordPolicyVersion :exec
+UPDATE users
+SET password_policy_version = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    password_policy_version = $3,
+    password_changed_at = NOW(),
+    must_change_password = FALSE,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetMustChangePassword :exec
+UPDATE users
+SET must_change_password = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdatePasswordHash :exec
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetVerificationToken :exec
+UPDATE users
+SET verification_token = $2, verification_token_expires_at = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserByVerificationToken :one
+SELECT * FROM users
+WHERE verification_token = $1
+AND verification_token_expires_at > NOW()
+LIMIT 1;
+
+-- name: MarkEmailVerified :exec
+UPDATE users
+SET email_verified = TRUE,
+    verification_token = NULL,
+    verification_token_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateUserEmail :exec
+UPDATE users
+SET email = $2, email_verified = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: LockUser :exec
+UPDATE users
+SET locked_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: UnlockUser :exec
+UPDATE users
+SET locked_at = NULL, mfa_failed_attempts = 0, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RequestAccountDeletion :exec
+UPDATE users
+SET deletion_requested_at = NOW(), deletion_restore_token_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RestoreAccountByToken :one
+UPDATE users
+SET deletion_requested_at = NULL, deletion_restore_token_hash = NULL, updated_at = NOW()
+WHERE deletion_restore_token_hash = $1
+AND deletion_requested_at > $2
+RETURNING id;
+
+-- name: RestoreAccount :exec
+UPDATE users
+SET deletion_requested_at = NULL, deletion_restore_token_hash = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ListUsersDueForPurge :many
+SELECT id FROM users
+WHERE deletion_requested_at < $1;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: ChangeUsername :exec
+UPDATE users
+SET username = $2, username_skeleton = $3, username_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetEmailOTPMFAEnabled :exec
+UPDATE users
+SET email_otp_mfa_enabled = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetPendingTOTPSecret :exec
+UPDATE users
+SET totp_secret_encrypted = $2, totp_enabled_at = NULL, totp_last_used_step = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: EnableTOTP :exec
+UPDATE users
+SET totp_enabled_at = NOW(), totp_last_used_step = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UseTOTPStep :execrows
+UPDATE users
+SET totp_last_used_step = $2
+WHERE id = $1 AND (totp_last_used_step IS NULL OR totp_last_used_step < $2);
+
+-- name: DisableTOTP :exec
+UPDATE users
+SET totp_secret_encrypted = NULL, totp_enabled_at = NULL, totp_last_used_step = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: IncrementMFAFailedAttempts :one
+UPDATE users
+SET mfa_failed_attempts = mfa_failed_attempts + 1
+WHERE id = $1
+RETURNING mfa_failed_attempts;
+
+-- name: ResetMFAFailedAttempts :exec
+UPDATE users
+SET mfa_failed_attempts = 0
+WHERE id = $1 AND mfa_failed_attempts <> 0;
+
+-- name: CountTOTPUsers :one
+SELECT COUNT(*) FROM users
+WHERE totp_enabled_at IS NOT NULL;
+
+-- name: SetPhoneNumber :exec
+UPDATE users
+SET phone_number = $2, phone_verified_at = NULL, phone_mfa_enabled = FALSE, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkPhoneVerified :exec
+UPDATE users
+SET phone_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND phone_number IS NOT NULL;
+
+-- name: SetPhoneMFAEnabled :exec
+UPDATE users
+SET phone_mfa_enabled = $2, updated_at = NOW()
+WHERE id = $1 AND phone_verified_at IS NOT NULL;
+
+-- name: ListUsersByStatus :many
+SELECT * FROM users
+WHERE status = $1
+ORDER BY created_at;
+
+-- name: UpdateUserStatus :execrows
+UPDATE users
+SET status = sqlc.arg(status), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status);
+
+-- name: CheckUsernameSkeletonExists :one
 SELECT EXISTS(
-    SELECT 1 FROM users WHERE username = $1
-) AS exists;
\ No newline at end of file
+    SELECT 1 FROM users WHERE username_skeleton = $1 AND id <> $2
+) AS exists;
+
+-- name: ListUsersWithoutSkeleton :many
+SELECT id, username FROM users
+WHERE username_skeleton = '';
+
+-- name: SetUsernameSkeleton :exec
+UPDATE users
+SET username_skeleton = $2
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,51 @@
+-- name: CreateWebAuthnCredential :one
+INSERT INTO webauthn_credentials (
+    user_id,
+    name,
+    credential_id,
+    public_key,
+    attestation_type,
+    aaguid,
+    sign_count,
+    transports,
+    user_verified,
+    backup_eligible,
+    backup_state
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: ListUserWebAuthnCredentials :many
+SELECT * FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: UpdateWebAuthnCredentialUsage :exec
+UPDATE webauthn_credentials
+SET sign_count = $2, backup_state = $3, last_used_at = NOW()
+WHERE credential_id = $1;
+
+-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWebAuthnChallenge :one
+INSERT INTO webauthn_challenges (
+    user_id,
+    purpose,
+    session_data,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id;
+
+-- name: ConsumeWebAuthnChallenge :one
+DELETE FROM webauthn_challenges
+WHERE id = $1
+AND purpose = $2
+AND expires_at > NOW()
+RETURNING user_id, session_data;
+
+-- name: DeleteExpiredWebAuthnChallenges :exec
+DELETE FROM webauthn_challenges
+WHERE expires_at <= NOW();
\ No newline at end of file
@@ -24,26 +24,362 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.anonymizeAuditEventsStmt, err = db.PrepareContext(ctx, anonymizeAuditEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query AnonymizeAuditEvents: %w", err)
+	}
+	if q.cancelPendingEmailChangesStmt, err = db.PrepareContext(ctx, cancelPendingEmailChanges); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelPendingEmailChanges: %w", err)
+	}
+	if q.changeUsernameStmt, err = db.PrepareContext(ctx, changeUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query ChangeUsername: %w", err)
+	}
 	if q.checkEmailExistsStmt, err = db.PrepareContext(ctx, checkEmailExists); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckEmailExists: %w", err)
 	}
 	if q.checkUsernameExistsStmt, err = db.PrepareContext(ctx, checkUsernameExists); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckUsernameExists: %w", err)
 	}
+	if q.checkUsernameSkeletonExistsStmt, err = db.PrepareContext(ctx, checkUsernameSkeletonExists); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckUsernameSkeletonExists: %w", err)
+	}
+	if q.completeDataExportStmt, err = db.PrepareContext(ctx, completeDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteDataExport: %w", err)
+	}
+	if q.confirmEmailChangeStmt, err = db.PrepareContext(ctx, confirmEmailChange); err != nil {
+		return nil, fmt.Errorf("error preparing query ConfirmEmailChange: %w", err)
+	}
+	if q.consumeMagicLinkTokenStmt, err = db.PrepareContext(ctx, consumeMagicLinkToken); err != nil {
+		return nil, fmt.Errorf("error preparing query ConsumeMagicLinkToken: %w", err)
+	}
+	if q.consumePasswordResetTokenStmt, err = db.PrepareContext(ctx, consumePasswordResetToken); err != nil {
+		return nil, fmt.Errorf("error preparing query ConsumePasswordResetToken: %w", err)
+	}
+	if q.consumeWebAuthnChallengeStmt, err = db.PrepareContext(ctx, consumeWebAuthnChallenge); err != nil {
+		return nil, fmt.Errorf("error preparing query ConsumeWebAuthnChallenge: %w", err)
+	}
+	if q.countMagicLinkTokensSinceStmt, err = db.PrepareContext(ctx, countMagicLinkTokensSince); err != nil {
+		return nil, fmt.Errorf("error preparing query CountMagicLinkTokensSince: %w", err)
+	}
+	if q.countOTPsSinceStmt, err = db.PrepareContext(ctx, countOTPsSince); err != nil {
+		return nil, fmt.Errorf("error preparing query CountOTPsSince: %w", err)
+	}
+	if q.countTOTPUsersStmt, err = db.PrepareContext(ctx, countTOTPUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountTOTPUsers: %w", err)
+	}
+	if q.countUnusedRecoveryCodesStmt, err = db.PrepareContext(ctx, countUnusedRecoveryCodes); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUnusedRecoveryCodes: %w", err)
+	}
+	if q.countUserExportRecordsStmt, err = db.PrepareContext(ctx, countUserExportRecords); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUserExportRecords: %w", err)
+	}
+	if q.createAuditEventStmt, err = db.PrepareContext(ctx, createAuditEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAuditEvent: %w", err)
+	}
+	if q.createDataExportStmt, err = db.PrepareContext(ctx, createDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDataExport: %w", err)
+	}
+	if q.createEmailChangeRequestStmt, err = db.PrepareContext(ctx, createEmailChangeRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateEmailChangeRequest: %w", err)
+	}
+	if q.createInviteStmt, err = db.PrepareContext(ctx, createInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateInvite: %w", err)
+	}
+	if q.createMagicLinkTokenStmt, err = db.PrepareContext(ctx, createMagicLinkToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMagicLinkToken: %w", err)
+	}
+	if q.createOTPStmt, err = db.PrepareContext(ctx, createOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateOTP: %w", err)
+	}
+	if q.createPasswordHistoryStmt, err = db.PrepareContext(ctx, createPasswordHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePasswordHistory: %w", err)
+	}
+	if q.createPasswordResetTokenStmt, err = db.PrepareContext(ctx, createPasswordResetToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePasswordResetToken: %w", err)
+	}
+	if q.createRecoveryCodeStmt, err = db.PrepareContext(ctx, createRecoveryCode); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRecoveryCode: %w", err)
+	}
+	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
+	}
 	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
 	}
+	if q.createUserIdentifierStmt, err = db.PrepareContext(ctx, createUserIdentifier); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUserIdentifier: %w", err)
+	}
+	if q.createWebAuthnChallengeStmt, err = db.PrepareContext(ctx, createWebAuthnChallenge); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebAuthnChallenge: %w", err)
+	}
+	if q.createWebAuthnCredentialStmt, err = db.PrepareContext(ctx, createWebAuthnCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebAuthnCredential: %w", err)
+	}
+	if q.deleteDataExportStmt, err = db.PrepareContext(ctx, deleteDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteDataExport: %w", err)
+	}
+	if q.deleteExpiredWebAuthnChallengesStmt, err = db.PrepareContext(ctx, deleteExpiredWebAuthnChallenges); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredWebAuthnChallenges: %w", err)
+	}
+	if q.deleteRecoveryCodesStmt, err = db.PrepareContext(ctx, deleteRecoveryCodes); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteRecoveryCodes: %w", err)
+	}
+	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
+	}
+	if q.deleteUserIdentifierStmt, err = db.PrepareContext(ctx, deleteUserIdentifier); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserIdentifier: %w", err)
+	}
+	if q.deleteWebAuthnCredentialStmt, err = db.PrepareContext(ctx, deleteWebAuthnCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebAuthnCredential: %w", err)
+	}
+	if q.disableTOTPStmt, err = db.PrepareContext(ctx, disableTOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query DisableTOTP: %w", err)
+	}
+	if q.enableTOTPStmt, err = db.PrepareContext(ctx, enableTOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query EnableTOTP: %w", err)
+	}
+	if q.failDataExportStmt, err = db.PrepareContext(ctx, failDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query FailDataExport: %w", err)
+	}
+	if q.getActiveOTPStmt, err = db.PrepareContext(ctx, getActiveOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query GetActiveOTP: %w", err)
+	}
+	if q.getDataExportStmt, err = db.PrepareContext(ctx, getDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDataExport: %w", err)
+	}
+	if q.getDataExportByTokenStmt, err = db.PrepareContext(ctx, getDataExportByToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDataExportByToken: %w", err)
+	}
+	if q.getInviteStmt, err = db.PrepareContext(ctx, getInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query GetInvite: %w", err)
+	}
+	if q.getPasswordResetTokenStmt, err = db.PrepareContext(ctx, getPasswordResetToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPasswordResetToken: %w", err)
+	}
+	if q.getUsableInviteStmt, err = db.PrepareContext(ctx, getUsableInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUsableInvite: %w", err)
+	}
 	if q.getUserByEmailStmt, err = db.PrepareContext(ctx, getUserByEmail); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByEmail: %w", err)
 	}
+	if q.getUserByIDStmt, err = db.PrepareContext(ctx, getUserByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByID: %w", err)
+	}
 	if q.getUserByUsernameStmt, err = db.PrepareContext(ctx, getUserByUsername); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByUsername: %w", err)
 	}
+	if q.getUserByVerificationTokenStmt, err = db.PrepareContext(ctx, getUserByVerificationToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByVerificationToken: %w", err)
+	}
+	if q.getUserIDByVerifiedIdentifierStmt, err = db.PrepareContext(ctx, getUserIDByVerifiedIdentifier); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserIDByVerifiedIdentifier: %w", err)
+	}
+	if q.getUserIdentifierStmt, err = db.PrepareContext(ctx, getUserIdentifier); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserIdentifier: %w", err)
+	}
+	if q.getUserProfileStmt, err = db.PrepareContext(ctx, getUserProfile); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserProfile: %w", err)
+	}
+	if q.getUsernameHoldOwnerStmt, err = db.PrepareContext(ctx, getUsernameHoldOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUsernameHoldOwner: %w", err)
+	}
+	if q.holdUsernameStmt, err = db.PrepareContext(ctx, holdUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query HoldUsername: %w", err)
+	}
+	if q.incrementMFAFailedAttemptsStmt, err = db.PrepareContext(ctx, incrementMFAFailedAttempts); err != nil {
+		return nil, fmt.Errorf("error preparing query IncrementMFAFailedAttempts: %w", err)
+	}
+	if q.incrementOTPAttemptsStmt, err = db.PrepareContext(ctx, incrementOTPAttempts); err != nil {
+		return nil, fmt.Errorf("error preparing query IncrementOTPAttempts: %w", err)
+	}
+	if q.invalidateMagicLinkTokensStmt, err = db.PrepareContext(ctx, invalidateMagicLinkTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query InvalidateMagicLinkTokens: %w", err)
+	}
+	if q.invalidateOTPsStmt, err = db.PrepareContext(ctx, invalidateOTPs); err != nil {
+		return nil, fmt.Errorf("error preparing query InvalidateOTPs: %w", err)
+	}
+	if q.invalidateUserOTPsStmt, err = db.PrepareContext(ctx, invalidateUserOTPs); err != nil {
+		return nil, fmt.Errorf("error preparing query InvalidateUserOTPs: %w", err)
+	}
+	if q.isSessionActiveStmt, err = db.PrepareContext(ctx, isSessionActive); err != nil {
+		return nil, fmt.Errorf("error preparing query IsSessionActive: %w", err)
+	}
+	if q.listExpiredDataExportsStmt, err = db.PrepareContext(ctx, listExpiredDataExports); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExpiredDataExports: %w", err)
+	}
+	if q.listInvitesStmt, err = db.PrepareContext(ctx, listInvites); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInvites: %w", err)
+	}
+	if q.listInvitesByInviterStmt, err = db.PrepareContext(ctx, listInvitesByInviter); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInvitesByInviter: %w", err)
+	}
+	if q.listPasswordHistoryStmt, err = db.PrepareContext(ctx, listPasswordHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPasswordHistory: %w", err)
+	}
+	if q.listUserAuditEventsStmt, err = db.PrepareContext(ctx, listUserAuditEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserAuditEvents: %w", err)
+	}
+	if q.listUserDataExportFilesStmt, err = db.PrepareContext(ctx, listUserDataExportFiles); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserDataExportFiles: %w", err)
+	}
+	if q.listUserEmailChangeRequestsStmt, err = db.PrepareContext(ctx, listUserEmailChangeRequests); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserEmailChangeRequests: %w", err)
+	}
+	if q.listUserIdentifiersStmt, err = db.PrepareContext(ctx, listUserIdentifiers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserIdentifiers: %w", err)
+	}
+	if q.listUserPasswordResetTokensStmt, err = db.PrepareContext(ctx, listUserPasswordResetTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserPasswordResetTokens: %w", err)
+	}
+	if q.listUserSessionsStmt, err = db.PrepareContext(ctx, listUserSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserSessions: %w", err)
+	}
+	if q.listUserUsernameHoldsStmt, err = db.PrepareContext(ctx, listUserUsernameHolds); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserUsernameHolds: %w", err)
+	}
+	if q.listUserWebAuthnCredentialsStmt, err = db.PrepareContext(ctx, listUserWebAuthnCredentials); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserWebAuthnCredentials: %w", err)
+	}
+	if q.listUsersByStatusStmt, err = db.PrepareContext(ctx, listUsersByStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsersByStatus: %w", err)
+	}
+	if q.listUsersDueForPurgeStmt, err = db.PrepareContext(ctx, listUsersDueForPurge); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsersDueForPurge: %w", err)
+	}
+	if q.listUsersWithoutSkeletonStmt, err = db.PrepareContext(ctx, listUsersWithoutSkeleton); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsersWithoutSkeleton: %w", err)
+	}
+	if q.lockUserStmt, err = db.PrepareContext(ctx, lockUser); err != nil {
+		return nil, fmt.Errorf("error preparing query LockUser: %w", err)
+	}
+	if q.markEmailVerifiedStmt, err = db.PrepareContext(ctx, markEmailVerified); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailVerified: %w", err)
+	}
+	if q.markPasswordResetTokensUsedStmt, err = db.PrepareContext(ctx, markPasswordResetTokensUsed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkPasswordResetTokensUsed: %w", err)
+	}
+	if q.markPhoneVerifiedStmt, err = db.PrepareContext(ctx, markPhoneVerified); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkPhoneVerified: %w", err)
+	}
+	if q.prunePasswordHistoryStmt, err = db.PrepareContext(ctx, prunePasswordHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query PrunePasswordHistory: %w", err)
+	}
+	if q.redeemInviteStmt, err = db.PrepareContext(ctx, redeemInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query RedeemInvite: %w", err)
+	}
+	if q.releaseUsernameHoldStmt, err = db.PrepareContext(ctx, releaseUsernameHold); err != nil {
+		return nil, fmt.Errorf("error preparing query ReleaseUsernameHold: %w", err)
+	}
+	if q.requestAccountDeletionStmt, err = db.PrepareContext(ctx, requestAccountDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query RequestAccountDeletion: %w", err)
+	}
+	if q.resetMFAFailedAttemptsStmt, err = db.PrepareContext(ctx, resetMFAFailedAttempts); err != nil {
+		return nil, fmt.Errorf("error preparing query ResetMFAFailedAttempts: %w", err)
+	}
+	if q.restoreAccountStmt, err = db.PrepareContext(ctx, restoreAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreAccount: %w", err)
+	}
+	if q.restoreAccountByTokenStmt, err = db.PrepareContext(ctx, restoreAccountByToken); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreAccountByToken: %w", err)
+	}
+	if q.revertEmailChangeStmt, err = db.PrepareContext(ctx, revertEmailChange); err != nil {
+		return nil, fmt.Errorf("error preparing query RevertEmailChange: %w", err)
+	}
+	if q.revokeInviteStmt, err = db.PrepareContext(ctx, revokeInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeInvite: %w", err)
+	}
+	if q.revokeOtherUserSessionsStmt, err = db.PrepareContext(ctx, revokeOtherUserSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeOtherUserSessions: %w", err)
+	}
+	if q.revokeSessionStmt, err = db.PrepareContext(ctx, revokeSession); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeSession: %w", err)
+	}
+	if q.revokeUserSessionsStmt, err = db.PrepareContext(ctx, revokeUserSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeUserSessions: %w", err)
+	}
+	if q.setEmailChangeRevertTokenStmt, err = db.PrepareContext(ctx, setEmailChangeRevertToken); err != nil {
+		return nil, fmt.Errorf("error preparing query SetEmailChangeRevertToken: %w", err)
+	}
+	if q.setEmailOTPMFAEnabledStmt, err = db.PrepareContext(ctx, setEmailOTPMFAEnabled); err != nil {
+		return nil, fmt.Errorf("error preparing query SetEmailOTPMFAEnabled: %w", err)
+	}
+	if q.setMustChangePasswordStmt, err = db.PrepareContext(ctx, setMustChangePassword); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMustChangePassword: %w", err)
+	}
+	if q.setPendingTOTPSecretStmt, err = db.PrepareContext(ctx, setPendingTOTPSecret); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPendingTOTPSecret: %w", err)
+	}
+	if q.setPhoneMFAEnabledStmt, err = db.PrepareContext(ctx, setPhoneMFAEnabled); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPhoneMFAEnabled: %w", err)
+	}
+	if q.setPhoneNumberStmt, err = db.PrepareContext(ctx, setPhoneNumber); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPhoneNumber: %w", err)
+	}
+	if q.setUserAvatarStmt, err = db.PrepareContext(ctx, setUserAvatar); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUserAvatar: %w", err)
+	}
+	if q.setUsernameSkeletonStmt, err = db.PrepareContext(ctx, setUsernameSkeleton); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUsernameSkeleton: %w", err)
+	}
+	if q.setVerificationTokenStmt, err = db.PrepareContext(ctx, setVerificationToken); err != nil {
+		return nil, fmt.Errorf("error preparing query SetVerificationToken: %w", err)
+	}
+	if q.unlockUserStmt, err = db.PrepareContext(ctx, unlockUser); err != nil {
+		return nil, fmt.Errorf("error preparing query UnlockUser: %w", err)
+	}
+	if q.updatePasswordHashStmt, err = db.PrepareContext(ctx, updatePasswordHash); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePasswordHash: %w", err)
+	}
+	if q.updatePasswordPolicyVersionStmt, err = db.PrepareContext(ctx, updatePasswordPolicyVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePasswordPolicyVersion: %w", err)
+	}
+	if q.updateUserEmailStmt, err = db.PrepareContext(ctx, updateUserEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserEmail: %w", err)
+	}
+	if q.updateUserPasswordStmt, err = db.PrepareContext(ctx, updateUserPassword); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserPassword: %w", err)
+	}
+	if q.updateUserStatusStmt, err = db.PrepareContext(ctx, updateUserStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserStatus: %w", err)
+	}
+	if q.updateWebAuthnCredentialUsageStmt, err = db.PrepareContext(ctx, updateWebAuthnCredentialUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateWebAuthnCredentialUsage: %w", err)
+	}
+	if q.upsertUserProfileStmt, err = db.PrepareContext(ctx, upsertUserProfile); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertUserProfile: %w", err)
+	}
+	if q.useOTPStmt, err = db.PrepareContext(ctx, useOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query UseOTP: %w", err)
+	}
+	if q.useRecoveryCodeStmt, err = db.PrepareContext(ctx, useRecoveryCode); err != nil {
+		return nil, fmt.Errorf("error preparing query UseRecoveryCode: %w", err)
+	}
+	if q.useTOTPStepStmt, err = db.PrepareContext(ctx, useTOTPStep); err != nil {
+		return nil, fmt.Errorf("error preparing query UseTOTPStep: %w", err)
+	}
+	if q.verifyUserIdentifierStmt, err = db.PrepareContext(ctx, verifyUserIdentifier); err != nil {
+		return nil, fmt.Errorf("error preparing query VerifyUserIdentifier: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.anonymizeAuditEventsStmt != nil {
+		if cerr := q.anonymizeAuditEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing anonymizeAuditEventsStmt: %w", cerr)
+		}
+	}
+	if q.cancelPendingEmailChangesStmt != nil {
+		if cerr := q.cancelPendingEmailChangesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelPendingEmailChangesStmt: %w", cerr)
+		}
+	}
+	if q.changeUsernameStmt != nil {
+		if cerr := q.changeUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing changeUsernameStmt: %w", cerr)
+		}
+	}
 	if q.checkEmailExistsStmt != nil {
 		if cerr := q.checkEmailExistsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing checkEmailExistsStmt: %w", cerr)
@@ -54,21 +390,541 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing checkUsernameExistsStmt: %w", cerr)
 		}
 	}
+	if q.checkUsernameSkeletonExistsStmt != nil {
+		if cerr := q.checkUsernameSkeletonExistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkUsernameSkeletonExistsStmt: %w", cerr)
+		}
+	}
+	if q.completeDataExportStmt != nil {
+		if cerr := q.completeDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeDataExportStmt: %w", cerr)
+		}
+	}
+	if q.confirmEmailChangeStmt != nil {
+		if cerr := q.confirmEmailChangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing confirmEmailChangeStmt: %w", cerr)
+		}
+	}
+	if q.consumeMagicLinkTokenStmt != nil {
+		if cerr := q.consumeMagicLinkTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing consumeMagicLinkTokenStmt: %w", cerr)
+		}
+	}
+	if q.consumePasswordResetTokenStmt != nil {
+		if cerr := q.consumePasswordResetTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing consumePasswordResetTokenStmt: %w", cerr)
+		}
+	}
+	if q.consumeWebAuthnChallengeStmt != nil {
+		if cerr := q.consumeWebAuthnChallengeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing consumeWebAuthnChallengeStmt: %w", cerr)
+		}
+	}
+	if q.countMagicLinkTokensSinceStmt != nil {
+		if cerr := q.countMagicLinkTokensSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countMagicLinkTokensSinceStmt: %w", cerr)
+		}
+	}
+	if q.countOTPsSinceStmt != nil {
+		if cerr := q.countOTPsSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countOTPsSinceStmt: %w", cerr)
+		}
+	}
+	if q.countTOTPUsersStmt != nil {
+		if cerr := q.countTOTPUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countTOTPUsersStmt: %w", cerr)
+		}
+	}
+	if q.countUnusedRecoveryCodesStmt != nil {
+		if cerr := q.countUnusedRecoveryCodesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUnusedRecoveryCodesStmt: %w", cerr)
+		}
+	}
+	if q.countUserExportRecordsStmt != nil {
+		if cerr := q.countUserExportRecordsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUserExportRecordsStmt: %w", cerr)
+		}
+	}
+	if q.createAuditEventStmt != nil {
+		if cerr := q.createAuditEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAuditEventStmt: %w", cerr)
+		}
+	}
+	if q.createDataExportStmt != nil {
+		if cerr := q.createDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDataExportStmt: %w", cerr)
+		}
+	}
+	if q.createEmailChangeRequestStmt != nil {
+		if cerr := q.createEmailChangeRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createEmailChangeRequestStmt: %w", cerr)
+		}
+	}
+	if q.createInviteStmt != nil {
+		if cerr := q.createInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createInviteStmt: %w", cerr)
+		}
+	}
+	if q.createMagicLinkTokenStmt != nil {
+		if cerr := q.createMagicLinkTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMagicLinkTokenStmt: %w", cerr)
+		}
+	}
+	if q.createOTPStmt != nil {
+		if cerr := q.createOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createOTPStmt: %w", cerr)
+		}
+	}
+	if q.createPasswordHistoryStmt != nil {
+		if cerr := q.createPasswordHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPasswordHistoryStmt: %w", cerr)
+		}
+	}
+	if q.createPasswordResetTokenStmt != nil {
+		if cerr := q.createPasswordResetTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPasswordResetTokenStmt: %w", cerr)
+		}
+	}
+	if q.createRecoveryCodeStmt != nil {
+		if cerr := q.createRecoveryCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRecoveryCodeStmt: %w", cerr)
+		}
+	}
+	if q.createSessionStmt != nil {
+		if cerr := q.createSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
+		}
+	}
 	if q.createUserStmt != nil {
 		if cerr := q.createUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
 		}
 	}
+	if q.createUserIdentifierStmt != nil {
+		if cerr := q.createUserIdentifierStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserIdentifierStmt: %w", cerr)
+		}
+	}
+	if q.createWebAuthnChallengeStmt != nil {
+		if cerr := q.createWebAuthnChallengeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebAuthnChallengeStmt: %w", cerr)
+		}
+	}
+	if q.createWebAuthnCredentialStmt != nil {
+		if cerr := q.createWebAuthnCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebAuthnCredentialStmt: %w", cerr)
+		}
+	}
+	if q.deleteDataExportStmt != nil {
+		if cerr := q.deleteDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteDataExportStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredWebAuthnChallengesStmt != nil {
+		if cerr := q.deleteExpiredWebAuthnChallengesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredWebAuthnChallengesStmt: %w", cerr)
+		}
+	}
+	if q.deleteRecoveryCodesStmt != nil {
+		if cerr := q.deleteRecoveryCodesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteRecoveryCodesStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserStmt != nil {
+		if cerr := q.deleteUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserIdentifierStmt != nil {
+		if cerr := q.deleteUserIdentifierStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserIdentifierStmt: %w", cerr)
+		}
+	}
+	if q.deleteWebAuthnCredentialStmt != nil {
+		if cerr := q.deleteWebAuthnCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebAuthnCredentialStmt: %w", cerr)
+		}
+	}
+	if q.disableTOTPStmt != nil {
+		if cerr := q.disableTOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing disableTOTPStmt: %w", cerr)
+		}
+	}
+	if q.enableTOTPStmt != nil {
+		if cerr := q.enableTOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enableTOTPStmt: %w", cerr)
+		}
+	}
+	if q.failDataExportStmt != nil {
+		if cerr := q.failDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failDataExportStmt: %w", cerr)
+		}
+	}
+	if q.getActiveOTPStmt != nil {
+		if cerr := q.getActiveOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getActiveOTPStmt: %w", cerr)
+		}
+	}
+	if q.getDataExportStmt != nil {
+		if cerr := q.getDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDataExportStmt: %w", cerr)
+		}
+	}
+	if q.getDataExportByTokenStmt != nil {
+		if cerr := q.getDataExportByTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDataExportByTokenStmt: %w", cerr)
+		}
+	}
+	if q.getInviteStmt != nil {
+		if cerr := q.getInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getInviteStmt: %w", cerr)
+		}
+	}
+	if q.getPasswordResetTokenStmt != nil {
+		if cerr := q.getPasswordResetTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPasswordResetTokenStmt: %w", cerr)
+		}
+	}
+	if q.getUsableInviteStmt != nil {
+		if cerr := q.getUsableInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUsableInviteStmt: %w", cerr)
+		}
+	}
 	if q.getUserByEmailStmt != nil {
 		if cerr := q.getUserByEmailStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserByEmailStmt: %w", cerr)
 		}
 	}
+	if q.getUserByIDStmt != nil {
+		if cerr := q.getUserByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByIDStmt: %w", cerr)
+		}
+	}
 	if q.getUserByUsernameStmt != nil {
 		if cerr := q.getUserByUsernameStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserByUsernameStmt: %w", cerr)
 		}
 	}
+	if q.getUserByVerificationTokenStmt != nil {
+		if cerr := q.getUserByVerificationTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByVerificationTokenStmt: %w", cerr)
+		}
+	}
+	if q.getUserIDByVerifiedIdentifierStmt != nil {
+		if cerr := q.getUserIDByVerifiedIdentifierStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserIDByVerifiedIdentifierStmt: %w", cerr)
+		}
+	}
+	if q.getUserIdentifierStmt != nil {
+		if cerr := q.getUserIdentifierStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserIdentifierStmt: %w", cerr)
+		}
+	}
+	if q.getUserProfileStmt != nil {
+		if cerr := q.getUserProfileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserProfileStmt: %w", cerr)
+		}
+	}
+	if q.getUsernameHoldOwnerStmt != nil {
+		if cerr := q.getUsernameHoldOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUsernameHoldOwnerStmt: %w", cerr)
+		}
+	}
+	if q.holdUsernameStmt != nil {
+		if cerr := q.holdUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing holdUsernameStmt: %w", cerr)
+		}
+	}
+	if q.incrementMFAFailedAttemptsStmt != nil {
+		if cerr := q.incrementMFAFailedAttemptsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing incrementMFAFailedAttemptsStmt: %w", cerr)
+		}
+	}
+	if q.incrementOTPAttemptsStmt != nil {
+		if cerr := q.incrementOTPAttemptsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing incrementOTPAttemptsStmt: %w", cerr)
+		}
+	}
+	if q.invalidateMagicLinkTokensStmt != nil {
+		if cerr := q.invalidateMagicLinkTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing invalidateMagicLinkTokensStmt: %w", cerr)
+		}
+	}
+	if q.invalidateOTPsStmt != nil {
+		if cerr := q.invalidateOTPsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing invalidateOTPsStmt: %w", cerr)
+		}
+	}
+	if q.invalidateUserOTPsStmt != nil {
+		if cerr := q.invalidateUserOTPsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing invalidateUserOTPsStmt: %w", cerr)
+		}
+	}
+	if q.isSessionActiveStmt != nil {
+		if cerr := q.isSessionActiveStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isSessionActiveStmt: %w", cerr)
+		}
+	}
+	if q.listExpiredDataExportsStmt != nil {
+		if cerr := q.listExpiredDataExportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExpiredDataExportsStmt: %w", cerr)
+		}
+	}
+	if q.listInvitesStmt != nil {
+		if cerr := q.listInvitesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInvitesStmt: %w", cerr)
+		}
+	}
+	if q.listInvitesByInviterStmt != nil {
+		if cerr := q.listInvitesByInviterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInvitesByInviterStmt: %w", cerr)
+		}
+	}
+	if q.listPasswordHistoryStmt != nil {
+		if cerr := q.listPasswordHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPasswordHistoryStmt: %w", cerr)
+		}
+	}
+	if q.listUserAuditEventsStmt != nil {
+		if cerr := q.listUserAuditEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserAuditEventsStmt: %w", cerr)
+		}
+	}
+	if q.listUserDataExportFilesStmt != nil {
+		if cerr := q.listUserDataExportFilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserDataExportFilesStmt: %w", cerr)
+		}
+	}
+	if q.listUserEmailChangeRequestsStmt != nil {
+		if cerr := q.listUserEmailChangeRequestsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserEmailChangeRequestsStmt: %w", cerr)
+		}
+	}
+	if q.listUserIdentifiersStmt != nil {
+		if cerr := q.listUserIdentifiersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserIdentifiersStmt: %w", cerr)
+		}
+	}
+	if q.listUserPasswordResetTokensStmt != nil {
+		if cerr := q.listUserPasswordResetTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserPasswordResetTokensStmt: %w", cerr)
+		}
+	}
+	if q.listUserSessionsStmt != nil {
+		if cerr := q.listUserSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserSessionsStmt: %w", cerr)
+		}
+	}
+	if q.listUserUsernameHoldsStmt != nil {
+		if cerr := q.listUserUsernameHoldsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserUsernameHoldsStmt: %w", cerr)
+		}
+	}
+	if q.listUserWebAuthnCredentialsStmt != nil {
+		if cerr := q.listUserWebAuthnCredentialsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserWebAuthnCredentialsStmt: %w", cerr)
+		}
+	}
+	if q.listUsersByStatusStmt != nil {
+		if cerr := q.listUsersByStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsersByStatusStmt: %w", cerr)
+		}
+	}
+	if q.listUsersDueForPurgeStmt != nil {
+		if cerr := q.listUsersDueForPurgeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsersDueForPurgeStmt: %w", cerr)
+		}
+	}
+	if q.listUsersWithoutSkeletonStmt != nil {
+		if cerr := q.listUsersWithoutSkeletonStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsersWithoutSkeletonStmt: %w", cerr)
+		}
+	}
+	if q.lockUserStmt != nil {
+		if cerr := q.lockUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing lockUserStmt: %w", cerr)
+		}
+	}
+	if q.markEmailVerifiedStmt != nil {
+		if cerr := q.markEmailVerifiedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailVerifiedStmt: %w", cerr)
+		}
+	}
+	if q.markPasswordResetTokensUsedStmt != nil {
+		if cerr := q.markPasswordResetTokensUsedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markPasswordResetTokensUsedStmt: %w", cerr)
+		}
+	}
+	if q.markPhoneVerifiedStmt != nil {
+		if cerr := q.markPhoneVerifiedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markPhoneVerifiedStmt: %w", cerr)
+		}
+	}
+	if q.prunePasswordHistoryStmt != nil {
+		if cerr := q.prunePasswordHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing prunePasswordHistoryStmt: %w", cerr)
+		}
+	}
+	if q.redeemInviteStmt != nil {
+		if cerr := q.redeemInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing redeemInviteStmt: %w", cerr)
+		}
+	}
+	if q.releaseUsernameHoldStmt != nil {
+		if cerr := q.releaseUsernameHoldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing releaseUsernameHoldStmt: %w", cerr)
+		}
+	}
+	if q.requestAccountDeletionStmt != nil {
+		if cerr := q.requestAccountDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requestAccountDeletionStmt: %w", cerr)
+		}
+	}
+	if q.resetMFAFailedAttemptsStmt != nil {
+		if cerr := q.resetMFAFailedAttemptsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resetMFAFailedAttemptsStmt: %w", cerr)
+		}
+	}
+	if q.restoreAccountStmt != nil {
+		if cerr := q.restoreAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreAccountStmt: %w", cerr)
+		}
+	}
+	if q.restoreAccountByTokenStmt != nil {
+		if cerr := q.restoreAccountByTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreAccountByTokenStmt: %w", cerr)
+		}
+	}
+	if q.revertEmailChangeStmt != nil {
+		if cerr := q.revertEmailChangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revertEmailChangeStmt: %w", cerr)
+		}
+	}
+	if q.revokeInviteStmt != nil {
+		if cerr := q.revokeInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeInviteStmt: %w", cerr)
+		}
+	}
+	if q.revokeOtherUserSessionsStmt != nil {
+		if cerr := q.revokeOtherUserSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeOtherUserSessionsStmt: %w", cerr)
+		}
+	}
+	if q.revokeSessionStmt != nil {
+		if cerr := q.revokeSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeSessionStmt: %w", cerr)
+		}
+	}
+	if q.revokeUserSessionsStmt != nil {
+		if cerr := q.revokeUserSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeUserSessionsStmt: %w", cerr)
+		}
+	}
+	if q.setEmailChangeRevertTokenStmt != nil {
+		if cerr := q.setEmailChangeRevertTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setEmailChangeRevertTokenStmt: %w", cerr)
+		}
+	}
+	if q.setEmailOTPMFAEnabledStmt != nil {
+		if cerr := q.setEmailOTPMFAEnabledStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setEmailOTPMFAEnabledStmt: %w", cerr)
+		}
+	}
+	if q.setMustChangePasswordStmt != nil {
+		if cerr := q.setMustChangePasswordStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMustChangePasswordStmt: %w", cerr)
+		}
+	}
+	if q.setPendingTOTPSecretStmt != nil {
+		if cerr := q.setPendingTOTPSecretStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPendingTOTPSecretStmt: %w", cerr)
+		}
+	}
+	if q.setPhoneMFAEnabledStmt != nil {
+		if cerr := q.setPhoneMFAEnabledStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPhoneMFAEnabledStmt: %w", cerr)
+		}
+	}
+	if q.setPhoneNumberStmt != nil {
+		if cerr := q.setPhoneNumberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPhoneNumberStmt: %w", cerr)
+		}
+	}
+	if q.setUserAvatarStmt != nil {
+		if cerr := q.setUserAvatarStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUserAvatarStmt: %w", cerr)
+		}
+	}
+	if q.setUsernameSkeletonStmt != nil {
+		if cerr := q.setUsernameSkeletonStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUsernameSkeletonStmt: %w", cerr)
+		}
+	}
+	if q.setVerificationTokenStmt != nil {
+		if cerr := q.setVerificationTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setVerificationTokenStmt: %w", cerr)
+		}
+	}
+	if q.unlockUserStmt != nil {
+		if cerr := q.unlockUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing unlockUserStmt: %w", cerr)
+		}
+	}
+	if q.updatePasswordHashStmt != nil {
+		if cerr := q.updatePasswordHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePasswordHashStmt: %w", cerr)
+		}
+	}
+	if q.updatePasswordPolicyVersionStmt != nil {
+		if cerr := q.updatePasswordPolicyVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePasswordPolicyVersionStmt: %w", cerr)
+		}
+	}
+	if q.updateUserEmailStmt != nil {
+		if cerr := q.updateUserEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserEmailStmt: %w", cerr)
+		}
+	}
+	if q.updateUserPasswordStmt != nil {
+		if cerr := q.updateUserPasswordStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserPasswordStmt: %w", cerr)
+		}
+	}
+	if q.updateUserStatusStmt != nil {
+		if cerr := q.updateUserStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserStatusStmt: %w", cerr)
+		}
+	}
+	if q.updateWebAuthnCredentialUsageStmt != nil {
+		if cerr := q.updateWebAuthnCredentialUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateWebAuthnCredentialUsageStmt: %w", cerr)
+		}
+	}
+	if q.upsertUserProfileStmt != nil {
+		if cerr := q.upsertUserProfileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertUserProfileStmt: %w", cerr)
+		}
+	}
+	if q.useOTPStmt != nil {
+		if cerr := q.useOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useOTPStmt: %w", cerr)
+		}
+	}
+	if q.useRecoveryCodeStmt != nil {
+		if cerr := q.useRecoveryCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useRecoveryCodeStmt: %w", cerr)
+		}
+	}
+	if q.useTOTPStepStmt != nil {
+		if cerr := q.useTOTPStepStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useTOTPStepStmt: %w", cerr)
+		}
+	}
+	if q.verifyUserIdentifierStmt != nil {
+		if cerr := q.verifyUserIdentifierStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing verifyUserIdentifierStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -106,23 +962,237 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                      DBTX
-	tx                      *sql.Tx
-	checkEmailExistsStmt    *sql.Stmt
-	checkUsernameExistsStmt *sql.Stmt
-	createUserStmt          *sql.Stmt
-	getUserByEmailStmt      *sql.Stmt
-	getUserByUsernameStmt   *sql.Stmt
+	db                                  DBTX
+	tx                                  *sql.Tx
+	anonymizeAuditEventsStmt            *sql.Stmt
+	cancelPendingEmailChangesStmt       *sql.Stmt
+	changeUsernameStmt                  *sql.Stmt
+	checkEmailExistsStmt                *sql.Stmt
+	checkUsernameExistsStmt             *sql.Stmt
+	checkUsernameSkeletonExistsStmt     *sql.Stmt
+	completeDataExportStmt              *sql.Stmt
+	confirmEmailChangeStmt              *sql.Stmt
+	consumeMagicLinkTokenStmt           *sql.Stmt
+	consumePasswordResetTokenStmt       *sql.Stmt
+	consumeWebAuthnChallengeStmt        *sql.Stmt
+	countMagicLinkTokensSinceStmt       *sql.Stmt
+	countOTPsSinceStmt                  *sql.Stmt
+	countTOTPUsersStmt                  *sql.Stmt
+	countUnusedRecoveryCodesStmt        *sql.Stmt
+	countUserExportRecordsStmt          *sql.Stmt
+	createAuditEventStmt                *sql.Stmt
+	createDataExportStmt                *sql.Stmt
+	createEmailChangeRequestStmt        *sql.Stmt
+	createInviteStmt                    *sql.Stmt
+	createMagicLinkTokenStmt            *sql.Stmt
+	createOTPStmt                       *sql.Stmt
+	createPasswordHistoryStmt           *sql.Stmt
+	createPasswordResetTokenStmt        *sql.Stmt
+	createRecoveryCodeStmt              *sql.Stmt
+	createSessionStmt                   *sql.Stmt
+	createUserStmt                      *sql.Stmt
+	createUserIdentifierStmt            *sql.Stmt
+	createWebAuthnChallengeStmt         *sql.Stmt
+	createWebAuthnCredentialStmt        *sql.Stmt
+	deleteDataExportStmt                *sql.Stmt
+	deleteExpiredWebAuthnChallengesStmt *sql.Stmt
+	deleteRecoveryCodesStmt             *sql.Stmt
+	deleteUserStmt                      *sql.Stmt
+	deleteUserIdentifierStmt            *sql.Stmt
+	deleteWebAuthnCredentialStmt        *sql.Stmt
+	disableTOTPStmt                     *sql.Stmt
+	enableTOTPStmt                      *sql.Stmt
+	failDataExportStmt                  *sql.Stmt
+	getActiveOTPStmt                    *sql.Stmt
+	getDataExportStmt                   *sql.Stmt
+	getDataExportByTokenStmt            *sql.Stmt
+	getInviteStmt                       *sql.Stmt
+	getPasswordResetTokenStmt           *sql.Stmt
+	getUsableInviteStmt                 *sql.Stmt
+	getUserByEmailStmt                  *sql.Stmt
+	getUserByIDStmt                     *sql.Stmt
+	getUserByUsernameStmt               *sql.Stmt
+	getUserByVerificationTokenStmt      *sql.Stmt
+	getUserIDByVerifiedIdentifierStmt   *sql.Stmt
+	getUserIdentifierStmt               *sql.Stmt
+	getUserProfileStmt                  *sql.Stmt
+	getUsernameHoldOwnerStmt            *sql.Stmt
+	holdUsernameStmt                    *sql.Stmt
+	incrementMFAFailedAttemptsStmt      *sql.Stmt
+	incrementOTPAttemptsStmt            *sql.Stmt
+	invalidateMagicLinkTokensStmt       *sql.Stmt
+	invalidateOTPsStmt                  *sql.Stmt
+	invalidateUserOTPsStmt              *sql.Stmt
+	isSessionActiveStmt                 *sql.Stmt
+	listExpiredDataExportsStmt          *sql.Stmt
+	listInvitesStmt                     *sql.Stmt
+	listInvitesByInviterStmt            *sql.Stmt
+	listPasswordHistoryStmt             *sql.Stmt
+	listUserAuditEventsStmt             *sql.Stmt
+	listUserDataExportFilesStmt         *sql.Stmt
+	listUserEmailChangeRequestsStmt     *sql.Stmt
+	listUserIdentifiersStmt             *sql.Stmt
+	listUserPasswordResetTokensStmt     *sql.Stmt
+	listUserSessionsStmt                *sql.Stmt
+	listUserUsernameHoldsStmt           *sql.Stmt
+	listUserWebAuthnCredentialsStmt     *sql.Stmt
+	listUsersByStatusStmt               *sql.Stmt
+	listUsersDueForPurgeStmt            *sql.Stmt
+	listUsersWithoutSkeletonStmt        *sql.Stmt
+	lockUserStmt                        *sql.Stmt
+	markEmailVerifiedStmt               *sql.Stmt
+	markPasswordResetTokensUsedStmt     *sql.Stmt
+	markPhoneVerifiedStmt               *sql.Stmt
+	prunePasswordHistoryStmt            *sql.Stmt
+	redeemInviteStmt                    *sql.Stmt
+	releaseUsernameHoldStmt             *sql.Stmt
+	requestAccountDeletionStmt          *sql.Stmt
+	resetMFAFailedAttemptsStmt          *sql.Stmt
+	restoreAccountStmt                  *sql.Stmt
+	restoreAccountByTokenStmt           *sql.Stmt
+	revertEmailChangeStmt               *sql.Stmt
+	revokeInviteStmt                    *sql.Stmt
+	revokeOtherUserSessionsStmt         *sql.Stmt
+	revokeSessionStmt                   *sql.Stmt
+	revokeUserSessionsStmt              *sql.Stmt
+	setEmailChangeRevertTokenStmt       *sql.Stmt
+	setEmailOTPMFAEnabledStmt           *sql.Stmt
+	setMustChangePasswordStmt           *sql.Stmt
+	setPendingTOTPSecretStmt            *sql.Stmt
+	setPhoneMFAEnabledStmt              *sql.Stmt
+	setPhoneNumberStmt                  *sql.Stmt
+	setUserAvatarStmt                   *sql.Stmt
+	setUsernameSkeletonStmt             *sql.Stmt
+	setVerificationTokenStmt            *sql.Stmt
+	unlockUserStmt                      *sql.Stmt
+	updatePasswordHashStmt              *sql.Stmt
+	updatePasswordPolicyVersionStmt     *sql.Stmt
+	updateUserEmailStmt                 *sql.Stmt
+	updateUserPasswordStmt              *sql.Stmt
+	updateUserStatusStmt                *sql.Stmt
+	updateWebAuthnCredentialUsageStmt   *sql.Stmt
+	upsertUserProfileStmt               *sql.Stmt
+	useOTPStmt                          *sql.Stmt
+	useRecoveryCodeStmt                 *sql.Stmt
+	useTOTPStepStmt                     *sql.Stmt
+	verifyUserIdentifierStmt            *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                      tx,
-		tx:                      tx,
-		checkEmailExistsStmt:    q.checkEmailExistsStmt,
-		checkUsernameExistsStmt: q.checkUsernameExistsStmt,
-		createUserStmt:          q.createUserStmt,
-		getUserByEmailStmt:      q.getUserByEmailStmt,
-		getUserByUsernameStmt:   q.getUserByUsernameStmt,
+		db:                                  tx,
+		tx:                                  tx,
+		anonymizeAuditEventsStmt:            q.anonymizeAuditEventsStmt,
+		cancelPendingEmailChangesStmt:       q.cancelPendingEmailChangesStmt,
+		changeUsernameStmt:                  q.changeUsernameStmt,
+		checkEmailExistsStmt:                q.checkEmailExistsStmt,
+		checkUsernameExistsStmt:             q.checkUsernameExistsStmt,
+		checkUsernameSkeletonExistsStmt:     q.checkUsernameSkeletonExistsStmt,
+		completeDataExportStmt:              q.completeDataExportStmt,
+		confirmEmailChangeStmt:              q.confirmEmailChangeStmt,
+		consumeMagicLinkTokenStmt:           q.consumeMagicLinkTokenStmt,
+		consumePasswordResetTokenStmt:       q.consumePasswordResetTokenStmt,
+		consumeWebAuthnChallengeStmt:        q.consumeWebAuthnChallengeStmt,
+		countMagicLinkTokensSinceStmt:       q.countMagicLinkTokensSinceStmt,
+		countOTPsSinceStmt:                  q.countOTPsSinceStmt,
+		countTOTPUsersStmt:                  q.countTOTPUsersStmt,
+		countUnusedRecoveryCodesStmt:        q.countUnusedRecoveryCodesStmt,
+		countUserExportRecordsStmt:          q.countUserExportRecordsStmt,
+		createAuditEventStmt:                q.createAuditEventStmt,
+		createDataExportStmt:                q.createDataExportStmt,
+		createEmailChangeRequestStmt:        q.createEmailChangeRequestStmt,
+		createInviteStmt:                    q.createInviteStmt,
+		createMagicLinkTokenStmt:            q.createMagicLinkTokenStmt,
+		createOTPStmt:                       q.createOTPStmt,
+		createPasswordHistoryStmt:           q.createPasswordHistoryStmt,
+		createPasswordResetTokenStmt:        q.createPasswordResetTokenStmt,
+		createRecoveryCodeStmt:              q.createRecoveryCodeStmt,
+		createSessionStmt:                   q.createSessionStmt,
+		createUserStmt:                      q.createUserStmt,
+		createUserIdentifierStmt:            q.createUserIdentifierStmt,
+		createWebAuthnChallengeStmt:         q.createWebAuthnChallengeStmt,
+		createWebAuthnCredentialStmt:        q.createWebAuthnCredentialStmt,
+		deleteDataExportStmt:                q.deleteDataExportStmt,
+		deleteExpiredWebAuthnChallengesStmt: q.deleteExpiredWebAuthnChallengesStmt,
+		deleteRecoveryCodesStmt:             q.deleteRecoveryCodesStmt,
+		deleteUserStmt:                      q.deleteUserStmt,
+		deleteUserIdentifierStmt:            q.deleteUserIdentifierStmt,
+		deleteWebAuthnCredentialStmt:        q.deleteWebAuthnCredentialStmt,
+		disableTOTPStmt:                     q.disableTOTPStmt,
+		enableTOTPStmt:                      q.enableTOTPStmt,
+		failDataExportStmt:                  q.failDataExportStmt,
+		getActiveOTPStmt:                    q.getActiveOTPStmt,
+		getDataExportStmt:                   q.getDataExportStmt,
+		getDataExportByTokenStmt:            q.getDataExportByTokenStmt,
+		getInviteStmt:                       q.getInviteStmt,
+		getPasswordResetTokenStmt:           q.getPasswordResetTokenStmt,
+		getUsableInviteStmt:                 q.getUsableInviteStmt,
+		getUserByEmailStmt:                  q.getUserByEmailStmt,
+		getUserByIDStmt:                     q.getUserByIDStmt,
+		getUserByUsernameStmt:               q.getUserByUsernameStmt,
+		getUserByVerificationTokenStmt:      q.getUserByVerificationTokenStmt,
+		getUserIDByVerifiedIdentifierStmt:   q.getUserIDByVerifiedIdentifierStmt,
+		getUserIdentifierStmt:               q.getUserIdentifierStmt,
+		getUserProfileStmt:                  q.getUserProfileStmt,
+		getUsernameHoldOwnerStmt:            q.getUsernameHoldOwnerStmt,
+		holdUsernameStmt:                    q.holdUsernameStmt,
+		incrementMFAFailedAttemptsStmt:      q.incrementMFAFailedAttemptsStmt,
+		incrementOTPAttemptsStmt:            q.incrementOTPAttemptsStmt,
+		invalidateMagicLinkTokensStmt:       q.invalidateMagicLinkTokensStmt,
+		invalidateOTPsStmt:                  q.invalidateOTPsStmt,
+		invalidateUserOTPsStmt:              q.invalidateUserOTPsStmt,
+		isSessionActiveStmt:                 q.isSessionActiveStmt,
+		listExpiredDataExportsStmt:          q.listExpiredDataExportsStmt,
+		listInvitesStmt:                     q.listInvitesStmt,
+		listInvitesByInviterStmt:            q.listInvitesByInviterStmt,
+		listPasswordHistoryStmt:             q.listPasswordHistoryStmt,
+		listUserAuditEventsStmt:             q.listUserAuditEventsStmt,
+		listUserDataExportFilesStmt:         q.listUserDataExportFilesStmt,
+		listUserEmailChangeRequestsStmt:     q.listUserEmailChangeRequestsStmt,
+		listUserIdentifiersStmt:             q.listUserIdentifiersStmt,
+		listUserPasswordResetTokensStmt:     q.listUserPasswordResetTokensStmt,
+		listUserSessionsStmt:                q.listUserSessionsStmt,
+		listUserUsernameHoldsStmt:           q.listUserUsernameHoldsStmt,
+		listUserWebAuthnCredentialsStmt:     q.listUserWebAuthnCredentialsStmt,
+		listUsersByStatusStmt:               q.listUsersByStatusStmt,
+		listUsersDueForPurgeStmt:            q.listUsersDueForPurgeStmt,
+		listUsersWithoutSkeletonStmt:        q.listUsersWithoutSkeletonStmt,
+		lockUserStmt:                        q.lockUserStmt,
+		markEmailVerifiedStmt:               q.markEmailVerifiedStmt,
+		markPasswordResetTokensUsedStmt:     q.markPasswordResetTokensUsedStmt,
+		markPhoneVerifiedStmt:               q.markPhoneVerifiedStmt,
+		prunePasswordHistoryStmt:            q.prunePasswordHistoryStmt,
+		redeemInviteStmt:                    q.redeemInviteStmt,
+		releaseUsernameHoldStmt:             q.releaseUsernameHoldStmt,
+		requestAccountDeletionStmt:          q.requestAccountDeletionStmt,
+		resetMFAFailedAttemptsStmt:          q.resetMFAFailedAttemptsStmt,
+		restoreAccountStmt:                  q.restoreAccountStmt,
+		restoreAccountByTokenStmt:           q.restoreAccountByTokenStmt,
+		revertEmailChangeStmt:               q.revertEmailChangeStmt,
+		revokeInviteStmt:                    q.revokeInviteStmt,
+		revokeOtherUserSessionsStmt:         q.revokeOtherUserSessionsStmt,
+		revokeSessionStmt:                   q.revokeSessionStmt,
+		revokeUserSessionsStmt:              q.revokeUserSessionsStmt,
+		setEmailChangeRevertTokenStmt:       q.setEmailChangeRevertTokenStmt,
+		setEmailOTPMFAEnabledStmt:           q.setEmailOTPMFAEnabledStmt,
+		setMustChangePasswordStmt:           q.setMustChangePasswordStmt,
+		setPendingTOTPSecretStmt:            q.setPendingTOTPSecretStmt,
+		setPhoneMFAEnabledStmt:              q.setPhoneMFAEnabledStmt,
+		setPhoneNumberStmt:                  q.setPhoneNumberStmt,
+		setUserAvatarStmt:                   q.setUserAvatarStmt,
+		setUsernameSkeletonStmt:             q.setUsernameSkeletonStmt,
+		setVerificationTokenStmt:            q.setVerificationTokenStmt,
+		unlockUserStmt:                      q.unlockUserStmt,
+		updatePasswordHashStmt:              q.updatePasswordHashStmt,
+		updatePasswordPolicyVersionStmt:     q.updatePasswordPolicyVersionStmt,
+		updateUserEmailStmt:                 q.updateUserEmailStmt,
+		updateUserPasswordStmt:              q.updateUserPasswordStmt,
+		updateUserStatusStmt:                q.updateUserStatusStmt,
+		updateWebAuthnCredentialUsageStmt:   q.updateWebAuthnCredentialUsageStmt,
+		upsertUserProfileStmt:               q.upsertUserProfileStmt,
+		useOTPStmt:                          q.useOTPStmt,
+		useRecoveryCodeStmt:                 q.useRecoveryCodeStmt,
+		useTOTPStepStmt:                     q.useTOTPStepStmt,
+		verifyUserIdentifierStmt:            q.verifyUserIdentifierStmt,
 	}
 }
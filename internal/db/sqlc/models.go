@@ -5,16 +5,195 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type User struct {
-	ID           uuid.UUID `json:"id"`
-	Email        string    `json:"email"`
-	Username     string    `json:"username"`
+type AuditEvent struct {
+	ID        int64           `json:"id"`
+	UserID    uuid.NullUUID   `json:"user_id"`
+	EventType string          `json:"event_type"`
+	IpAddress string          `json:"ip_address"`
+	UserAgent string          `json:"user_agent"`
+	Metadata  json.RawMessage `json:"metadata"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type DataExport struct {
+	ID                uuid.UUID      `json:"id"`
+	UserID            uuid.UUID      `json:"user_id"`
+	Status            string         `json:"status"`
+	FilePath          sql.NullString `json:"file_path"`
+	DownloadTokenHash sql.NullString `json:"download_token_hash"`
+	ExpiresAt         sql.NullTime   `json:"expires_at"`
+	CreatedAt         time.Time      `json:"created_at"`
+	CompletedAt       sql.NullTime   `json:"completed_at"`
+}
+
+type EmailChangeRequest struct {
+	ID               uuid.UUID      `json:"id"`
+	UserID           uuid.UUID      `json:"user_id"`
+	OldEmail         string         `json:"old_email"`
+	OldEmailVerified bool           `json:"old_email_verified"`
+	NewEmail         string         `json:"new_email"`
+	ConfirmTokenHash string         `json:"confirm_token_hash"`
+	ConfirmExpiresAt time.Time      `json:"confirm_expires_at"`
+	ConfirmedAt      sql.NullTime   `json:"confirmed_at"`
+	RevertTokenHash  sql.NullString `json:"revert_token_hash"`
+	RevertExpiresAt  sql.NullTime   `json:"revert_expires_at"`
+	RevertedAt       sql.NullTime   `json:"reverted_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+type Invite struct {
+	ID        uuid.UUID      `json:"id"`
+	CodeHash  string         `json:"code_hash"`
+	InviterID uuid.NullUUID  `json:"inviter_id"`
+	Email     sql.NullString `json:"email"`
+	MaxUses   int32          `json:"max_uses"`
+	Uses      int32          `json:"uses"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	RevokedAt sql.NullTime   `json:"revoked_at"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type MagicLinkToken struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	NonceHash string       `json:"nonce_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type OtpCode struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Purpose   string       `json:"purpose"`
+	CodeHash  string       `json:"code_hash"`
+	Attempts  int32        `json:"attempts"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PasswordHistory struct {
+	ID           int64     `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
 	PasswordHash string    `json:"password_hash"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type PasswordResetToken struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Session struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	UserAgent string       `json:"user_agent"`
+	IpAddress string       `json:"ip_address"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+}
+
+type User struct {
+	ID                         uuid.UUID      `json:"id"`
+	Email                      string         `json:"email"`
+	Username                   string         `json:"username"`
+	PasswordHash               string         `json:"password_hash"`
+	CreatedAt                  time.Time      `json:"created_at"`
+	UpdatedAt                  time.Time      `json:"updated_at"`
+	EmailVerified              bool           `json:"email_verified"`
+	VerificationToken          sql.NullString `json:"verification_token"`
+	Role                       string         `json:"role"`
+	PasswordPolicyVersion      int32          `json:"password_policy_version"`
+	PasswordChangedAt          time.Time      `json:"password_changed_at"`
+	MustChangePassword         bool           `json:"must_change_password"`
+	VerificationTokenExpiresAt sql.NullTime   `json:"verification_token_expires_at"`
+	LockedAt                   sql.NullTime   `json:"locked_at"`
+	DeletionRequestedAt        sql.NullTime   `json:"deletion_requested_at"`
+	DeletionRestoreTokenHash   sql.NullString `json:"deletion_restore_token_hash"`
+	UsernameChangedAt          sql.NullTime   `json:"username_changed_at"`
+	EmailOtpMfaEnabled         bool           `json:"email_otp_mfa_enabled"`
+	TotpSecretEncrypted        sql.NullString `json:"totp_secret_encrypted"`
+	TotpEnabledAt              sql.NullTime   `json:"totp_enabled_at"`
+	TotpLastUsedStep           sql.NullInt64  `json:"totp_last_used_step"`
+	PhoneNumber                sql.NullString `json:"phone_number"`
+	PhoneVerifiedAt            sql.NullTime   `json:"phone_verified_at"`
+	PhoneMfaEnabled            bool           `json:"phone_mfa_enabled"`
+	Status                     string         `json:"status"`
+	UsernameSkeleton           string         `json:"username_skeleton"`
+	MfaFailedAttempts          int32          `json:"mfa_failed_attempts"`
+}
+
+type UserIdentifier struct {
+	ID                    uuid.UUID      `json:"id"`
+	UserID                uuid.UUID      `json:"user_id"`
+	Email                 string         `json:"email"`
+	VerificationTokenHash sql.NullString `json:"verification_token_hash"`
+	VerificationExpiresAt sql.NullTime   `json:"verification_expires_at"`
+	VerifiedAt            sql.NullTime   `json:"verified_at"`
+	CreatedAt             time.Time      `json:"created_at"`
+}
+
+type UserProfile struct {
+	UserID      uuid.UUID      `json:"user_id"`
+	DisplayName string         `json:"display_name"`
+	Bio         string         `json:"bio"`
+	Locale      string         `json:"locale"`
+	Timezone    string         `json:"timezone"`
+	AvatarKey   sql.NullString `json:"avatar_key"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+type UsernameHold struct {
+	Username  string    `json:"username"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebauthnChallenge struct {
+	ID          uuid.UUID       `json:"id"`
+	UserID      uuid.NullUUID   `json:"user_id"`
+	Purpose     string          `json:"purpose"`
+	SessionData json.RawMessage `json:"session_data"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type WebauthnCredential struct {
+	ID              uuid.UUID    `json:"id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	Name            string       `json:"name"`
+	CredentialID    []byte       `json:"credential_id"`
+	PublicKey       []byte       `json:"public_key"`
+	AttestationType string       `json:"attestation_type"`
+	Aaguid          []byte       `json:"aaguid"`
+	SignCount       int64        `json:"sign_count"`
+	Transports      []string     `json:"transports"`
+	UserVerified    bool         `json:"user_verified"`
+	BackupEligible  bool         `json:"backup_eligible"`
+	BackupState     bool         `json:"backup_state"`
+	LastUsedAt      sql.NullTime `json:"last_used_at"`
+	CreatedAt       time.Time    `json:"created_at"`
 }
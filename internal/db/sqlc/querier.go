@@ -6,14 +6,124 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AnonymizeAuditEvents(ctx context.Context, userID uuid.NullUUID) error
+	CancelPendingEmailChanges(ctx context.Context, userID uuid.UUID) error
+	ChangeUsername(ctx context.Context, arg ChangeUsernameParams) error
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
 	CheckUsernameExists(ctx context.Context, username string) (bool, error)
+	CheckUsernameSkeletonExists(ctx context.Context, arg CheckUsernameSkeletonExistsParams) (bool, error)
+	CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) error
+	ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (EmailChangeRequest, error)
+	ConsumeMagicLinkToken(ctx context.Context, arg ConsumeMagicLinkTokenParams) (uuid.UUID, error)
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	ConsumeWebAuthnChallenge(ctx context.Context, arg ConsumeWebAuthnChallengeParams) (ConsumeWebAuthnChallengeRow, error)
+	CountMagicLinkTokensSince(ctx context.Context, arg CountMagicLinkTokensSinceParams) (int64, error)
+	CountOTPsSince(ctx context.Context, arg CountOTPsSinceParams) (int64, error)
+	CountTOTPUsers(ctx context.Context) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountUserExportRecords(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateDataExport(ctx context.Context, userID uuid.UUID) (DataExport, error)
+	CreateEmailChangeRequest(ctx context.Context, arg CreateEmailChangeRequestParams) (EmailChangeRequest, error)
+	CreateInvite(ctx context.Context, arg CreateInviteParams) (Invite, error)
+	CreateMagicLinkToken(ctx context.Context, arg CreateMagicLinkTokenParams) error
+	CreateOTP(ctx context.Context, arg CreateOTPParams) error
+	CreatePasswordHistory(ctx context.Context, arg CreatePasswordHistoryParams) error
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentifier(ctx context.Context, arg CreateUserIdentifierParams) (UserIdentifier, error)
+	CreateWebAuthnChallenge(ctx context.Context, arg CreateWebAuthnChallengeParams) (uuid.UUID, error)
+	CreateWebAuthnCredential(ctx context.Context, arg CreateWebAuthnCredentialParams) (WebauthnCredential, error)
+	DeleteDataExport(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredWebAuthnChallenges(ctx context.Context) error
+	DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteUserIdentifier(ctx context.Context, arg DeleteUserIdentifierParams) (int64, error)
+	DeleteWebAuthnCredential(ctx context.Context, arg DeleteWebAuthnCredentialParams) (int64, error)
+	DisableTOTP(ctx context.Context, id uuid.UUID) error
+	EnableTOTP(ctx context.Context, arg EnableTOTPParams) error
+	FailDataExport(ctx context.Context, id uuid.UUID) error
+	GetActiveOTP(ctx context.Context, arg GetActiveOTPParams) (OtpCode, error)
+	GetDataExport(ctx context.Context, arg GetDataExportParams) (DataExport, error)
+	GetDataExportByToken(ctx context.Context, arg GetDataExportByTokenParams) (DataExport, error)
+	GetInvite(ctx context.Context, id uuid.UUID) (Invite, error)
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetUsableInvite(ctx context.Context, codeHash string) (Invite, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserByVerificationToken(ctx context.Context, verificationToken sql.NullString) (User, error)
+	GetUserIDByVerifiedIdentifier(ctx context.Context, email string) (uuid.UUID, error)
+	GetUserIdentifier(ctx context.Context, arg GetUserIdentifierParams) (UserIdentifier, error)
+	GetUserProfile(ctx context.Context, userID uuid.UUID) (UserProfile, error)
+	GetUsernameHoldOwner(ctx context.Context, username string) (uuid.UUID, error)
+	HoldUsername(ctx context.Context, arg HoldUsernameParams) error
+	IncrementMFAFailedAttempts(ctx context.Context, id uuid.UUID) (int32, error)
+	IncrementOTPAttempts(ctx context.Context, id uuid.UUID) (int32, error)
+	InvalidateMagicLinkTokens(ctx context.Context, userID uuid.UUID) error
+	InvalidateOTPs(ctx context.Context, arg InvalidateOTPsParams) error
+	InvalidateUserOTPs(ctx context.Context, userID uuid.UUID) error
+	IsSessionActive(ctx context.Context, id uuid.UUID) (bool, error)
+	ListExpiredDataExports(ctx context.Context) ([]DataExport, error)
+	ListInvites(ctx context.Context) ([]Invite, error)
+	ListInvitesByInviter(ctx context.Context, inviterID uuid.NullUUID) ([]Invite, error)
+	ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]string, error)
+	ListUserAuditEvents(ctx context.Context, userID uuid.NullUUID) ([]AuditEvent, error)
+	ListUserDataExportFiles(ctx context.Context, userID uuid.UUID) ([]sql.NullString, error)
+	ListUserEmailChangeRequests(ctx context.Context, userID uuid.UUID) ([]ListUserEmailChangeRequestsRow, error)
+	ListUserIdentifiers(ctx context.Context, userID uuid.UUID) ([]UserIdentifier, error)
+	ListUserPasswordResetTokens(ctx context.Context, userID uuid.UUID) ([]ListUserPasswordResetTokensRow, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	ListUserUsernameHolds(ctx context.Context, userID uuid.UUID) ([]ListUserUsernameHoldsRow, error)
+	ListUserWebAuthnCredentials(ctx context.Context, userID uuid.UUID) ([]WebauthnCredential, error)
+	ListUsersByStatus(ctx context.Context, status string) ([]User, error)
+	ListUsersDueForPurge(ctx context.Context, deletionRequestedAt sql.NullTime) ([]uuid.UUID, error)
+	ListUsersWithoutSkeleton(ctx context.Context) ([]ListUsersWithoutSkeletonRow, error)
+	LockUser(ctx context.Context, id uuid.UUID) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
+	MarkPasswordResetTokensUsed(ctx context.Context, userID uuid.UUID) error
+	MarkPhoneVerified(ctx context.Context, id uuid.UUID) error
+	PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) error
+	RedeemInvite(ctx context.Context, codeHash string) (Invite, error)
+	ReleaseUsernameHold(ctx context.Context, username string) error
+	RequestAccountDeletion(ctx context.Context, arg RequestAccountDeletionParams) error
+	ResetMFAFailedAttempts(ctx context.Context, id uuid.UUID) error
+	RestoreAccount(ctx context.Context, id uuid.UUID) error
+	RestoreAccountByToken(ctx context.Context, arg RestoreAccountByTokenParams) (uuid.UUID, error)
+	RevertEmailChange(ctx context.Context, revertTokenHash sql.NullString) (EmailChangeRequest, error)
+	RevokeInvite(ctx context.Context, id uuid.UUID) error
+	RevokeOtherUserSessions(ctx context.Context, arg RevokeOtherUserSessionsParams) error
+	RevokeSession(ctx context.Context, id uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	SetEmailChangeRevertToken(ctx context.Context, arg SetEmailChangeRevertTokenParams) error
+	SetEmailOTPMFAEnabled(ctx context.Context, arg SetEmailOTPMFAEnabledParams) error
+	SetMustChangePassword(ctx context.Context, arg SetMustChangePasswordParams) error
+	SetPendingTOTPSecret(ctx context.Context, arg SetPendingTOTPSecretParams) error
+	SetPhoneMFAEnabled(ctx context.Context, arg SetPhoneMFAEnabledParams) error
+	SetPhoneNumber(ctx context.Context, arg SetPhoneNumberParams) error
+	SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) error
+	SetUsernameSkeleton(ctx context.Context, arg SetUsernameSkeletonParams) error
+	SetVerificationToken(ctx context.Context, arg SetVerificationTokenParams) error
+	UnlockUser(ctx context.Context, id uuid.UUID) error
+	UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) error
+	UpdatePasswordPolicyVersion(ctx context.Context, arg UpdatePasswordPolicyVersionParams) error
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) error
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (int64, error)
+	UpdateWebAuthnCredentialUsage(ctx context.Context, arg UpdateWebAuthnCredentialUsageParams) error
+	UpsertUserProfile(ctx context.Context, arg UpsertUserProfileParams) (UserProfile, error)
+	UseOTP(ctx context.Context, id uuid.UUID) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
+	VerifyUserIdentifier(ctx context.Context, verificationTokenHash sql.NullString) (UserIdentifier, error)
 }
 
 var _ Querier = (*Queries)(nil)
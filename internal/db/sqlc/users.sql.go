@@ -7,11 +7,33 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
+const changeUsername = `-- name: ChangeUsername :exec
+UPDATE users
+SET username = $2, username_skeleton = $3, username_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+type ChangeUsernameParams struct {
+	ID               uuid.UUID `json:"id"`
+	Username         string    `json:"username"`
+	UsernameSkeleton string    `json:"username_skeleton"`
+}
+
+func (q *Queries) ChangeUsername(ctx context.Context, arg ChangeUsernameParams) error {
+	_, err := q.exec(ctx, q.changeUsernameStmt, changeUsername, arg.ID, arg.Username, arg.UsernameSkeleton)
+	return err
+}
+
 const checkEmailExists = `-- name: CheckEmailExists :one
 SELECT EXISTS(
     SELECT 1 FROM users WHERE email = $1
+    UNION ALL
+    SELECT 1 FROM user_identifiers WHERE email = $1 AND verified_at IS NOT NULL
 ) AS exists
 `
 
@@ -23,9 +45,10 @@ func (q *Queries) CheckEmailExists(ctx context.Context, email string) (bool, err
 }
 
 const checkUsernameExists = `-- name: CheckUsernameExists :one
-SELECT EXISTS(
-    SELECT 1 FROM users WHERE username = $1
-) AS exists
+SELECT (
+    EXISTS(SELECT 1 FROM users WHERE users.username = $1) OR
+    EXISTS(SELECT 1 FROM username_holds WHERE username_holds.username = $1 AND expires_at > NOW())
+)::BOOLEAN AS exists
 `
 
 func (q *Queries) CheckUsernameExists(ctx context.Context, username string) (bool, error) {
@@ -35,24 +58,67 @@ func (q *Queries) CheckUsernameExists(ctx context.Context, username string) (boo
 	return exists, err
 }
 
+const checkUsernameSkeletonExists = `-- name: CheckUsernameSkeletonExists :one
+SELECT EXISTS(
+    SELECT 1 FROM users WHERE username_skeleton = $1 AND id <> $2
+) AS exists
+`
+
+type CheckUsernameSkeletonExistsParams struct {
+	UsernameSkeleton string    `json:"username_skeleton"`
+	ID               uuid.UUID `json:"id"`
+}
+
+func (q *Queries) CheckUsernameSkeletonExists(ctx context.Context, arg CheckUsernameSkeletonExistsParams) (bool, error) {
+	row := q.queryRow(ctx, q.checkUsernameSkeletonExistsStmt, checkUsernameSkeletonExists, arg.UsernameSkeleton, arg.ID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const countTOTPUsers = `-- name: CountTOTPUsers :one
+SELECT COUNT(*) FROM users
+WHERE totp_enabled_at IS NOT NULL
+`
+
+func (q *Queries) CountTOTPUsers(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countTOTPUsersStmt, countTOTPUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     email,
     username,
-    password_hash
+    password_hash,
+    password_policy_version,
+    status,
+    username_skeleton
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, email, username, password_hash, created_at, updated_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, email, username, password_hash, created_at, updated_at, email_verified, verification_token, role, password_policy_version, password_changed_at, must_change_password, verification_token_expires_at, locked_at, deletion_requested_at, deletion_restore_token_hash, username_changed_at, email_otp_mfa_enabled, totp_secret_encrypted, totp_enabled_at, totp_last_used_step, phone_number, phone_verified_at, phone_mfa_enabled, status, username_skeleton, mfa_failed_attempts
 `
 
 type CreateUserParams struct {
-	Email        string `json:"email"`
-	Username     string `json:"username"`
-	PasswordHash string `json:"password_hash"`
+	Email                 string `json:"email"`
+	Username              string `json:"username"`
+	PasswordHash          string `json:"password_hash"`
+	PasswordPolicyVersion int32  `json:"password_policy_version"`
+	Status                string `json:"status"`
+	UsernameSkeleton      string `json:"username_skeleton"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.queryRow(ctx, q.createUserStmt, createUser, arg.Email, arg.Username, arg.PasswordHash)
+	row := q.queryRow(ctx, q.createUserStmt, createUser,
+		arg.Email,
+		arg.Username,
+		arg.PasswordHash,
+		arg.PasswordPolicyVersion,
+		arg.Status,
+		arg.UsernameSkeleton,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -61,12 +127,70 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.VerificationToken,
+		&i.Role,
+		&i.PasswordPolicyVersion,
+		&i.PasswordChangedAt,
+		&i.MustChangePassword,
+		&i.VerificationTokenExpiresAt,
+		&i.LockedAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionRestoreTokenHash,
+		&i.UsernameChangedAt,
+		&i.EmailOtpMfaEnabled,
+		&i.TotpSecretEncrypted,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.PhoneMfaEnabled,
+		&i.Status,
+		&i.UsernameSkeleton,
+		&i.MfaFailedAttempts,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.deleteUserStmt, deleteUser, id)
+	return err
+}
+
+const disableTOTP = `-- name: DisableTOTP :exec
+UPDATE users
+SET totp_secret_encrypted = NULL, totp_enabled_at = NULL, totp_last_used_step = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DisableTOTP(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.disableTOTPStmt, disableTOTP, id)
+	return err
+}
+
+const enableTOTP = `-- name: EnableTOTP :exec
+UPDATE users
+SET totp_enabled_at = NOW(), totp_last_used_step = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type EnableTOTPParams struct {
+	ID               uuid.UUID     `json:"id"`
+	TotpLastUsedStep sql.NullInt64 `json:"totp_last_used_step"`
+}
+
+func (q *Queries) EnableTOTP(ctx context.Context, arg EnableTOTPParams) error {
+	_, err := q.exec(ctx, q.enableTOTPStmt, enableTOTP, arg.ID, arg.TotpLastUsedStep)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, username, password_hash, created_at, updated_at FROM users
+SELECT id, email, username, password_hash, created_at, updated_at, email_verified, verification_token, role, password_policy_version, password_changed_at, must_change_password, verification_token_expires_at, locked_at, deletion_requested_at, deletion_restore_token_hash, username_changed_at, email_otp_mfa_enabled, totp_secret_encrypted, totp_enabled_at, totp_last_used_step, phone_number, phone_verified_at, phone_mfa_enabled, status, username_skeleton, mfa_failed_attempts FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -80,12 +204,73 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.VerificationToken,
+		&i.Role,
+		&i.PasswordPolicyVersion,
+		&i.PasswordChangedAt,
+		&i.MustChangePassword,
+		&i.VerificationTokenExpiresAt,
+		&i.LockedAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionRestoreTokenHash,
+		&i.UsernameChangedAt,
+		&i.EmailOtpMfaEnabled,
+		&i.TotpSecretEncrypted,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.PhoneMfaEnabled,
+		&i.Status,
+		&i.UsernameSkeleton,
+		&i.MfaFailedAttempts,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email, username, password_hash, created_at, updated_at, email_verified, verification_token, role, password_policy_version, password_changed_at, must_change_password, verification_token_expires_at, locked_at, deletion_requested_at, deletion_restore_token_hash, username_changed_at, email_otp_mfa_enabled, totp_secret_encrypted, totp_enabled_at, totp_last_used_step, phone_number, phone_verified_at, phone_mfa_enabled, status, username_skeleton, mfa_failed_attempts FROM users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.queryRow(ctx, q.getUserByIDStmt, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.VerificationToken,
+		&i.Role,
+		&i.PasswordPolicyVersion,
+		&i.PasswordChangedAt,
+		&i.MustChangePassword,
+		&i.VerificationTokenExpiresAt,
+		&i.LockedAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionRestoreTokenHash,
+		&i.UsernameChangedAt,
+		&i.EmailOtpMfaEnabled,
+		&i.TotpSecretEncrypted,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.PhoneMfaEnabled,
+		&i.Status,
+		&i.UsernameSkeleton,
+		&i.MfaFailedAttempts,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, email, username, password_hash, created_at, updated_at FROM users
+SELECT id, email, username, password_hash, created_at, updated_at, email_verified, verification_token, role, password_policy_version, password_changed_at, must_change_password, verification_token_expires_at, locked_at, deletion_requested_at, deletion_restore_token_hash, username_changed_at, email_otp_mfa_enabled, totp_secret_encrypted, totp_enabled_at, totp_last_used_step, phone_number, phone_verified_at, phone_mfa_enabled, status, username_skeleton, mfa_failed_attempts FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -99,6 +284,528 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.VerificationToken,
+		&i.Role,
+		&i.PasswordPolicyVersion,
+		&i.PasswordChangedAt,
+		&i.MustChangePassword,
+		&i.VerificationTokenExpiresAt,
+		&i.LockedAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionRestoreTokenHash,
+		&i.UsernameChangedAt,
+		&i.EmailOtpMfaEnabled,
+		&i.TotpSecretEncrypted,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.PhoneMfaEnabled,
+		&i.Status,
+		&i.UsernameSkeleton,
+		&i.MfaFailedAttempts,
 	)
 	return i, err
 }
+
+const getUserByVerificationToken = `-- name: GetUserByVerificationToken :one
+SELECT id, email, username, password_hash, created_at, updated_at, email_verified, verification_token, role, password_policy_version, password_changed_at, must_change_password, verification_token_expires_at, locked_at, deletion_requested_at, deletion_restore_token_hash, username_changed_at, email_otp_mfa_enabled, totp_secret_encrypted, totp_enabled_at, totp_last_used_step, phone_number, phone_verified_at, phone_mfa_enabled, status, username_skeleton, mfa_failed_attempts FROM users
+WHERE verification_token = $1
+AND verification_token_expires_at > NOW()
+LIMIT 1
+`
+
+func (q *Queries) GetUserByVerificationToken(ctx context.Context, verificationToken sql.NullString) (User, error) {
+	row := q.queryRow(ctx, q.getUserByVerificationTokenStmt, getUserByVerificationToken, verificationToken)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerified,
+		&i.VerificationToken,
+		&i.Role,
+		&i.PasswordPolicyVersion,
+		&i.PasswordChangedAt,
+		&i.MustChangePassword,
+		&i.VerificationTokenExpiresAt,
+		&i.LockedAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionRestoreTokenHash,
+		&i.UsernameChangedAt,
+		&i.EmailOtpMfaEnabled,
+		&i.TotpSecretEncrypted,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.PhoneMfaEnabled,
+		&i.Status,
+		&i.UsernameSkeleton,
+		&i.MfaFailedAttempts,
+	)
+	return i, err
+}
+
+const incrementMFAFailedAttempts = `-- name: IncrementMFAFailedAttempts :one
+UPDATE users
+SET mfa_failed_attempts = mfa_failed_attempts + 1
+WHERE id = $1
+RETURNING mfa_failed_attempts
+`
+
+func (q *Queries) IncrementMFAFailedAttempts(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.queryRow(ctx, q.incrementMFAFailedAttemptsStmt, incrementMFAFailedAttempts, id)
+	var mfa_failed_attempts int32
+	err := row.Scan(&mfa_failed_attempts)
+	return mfa_failed_attempts, err
+}
+
+const listUsersByStatus = `-- name: ListUsersByStatus :many
+SELECT id, email, username, password_hash, created_at, updated_at, email_verified, verification_token, role, password_policy_version, password_changed_at, must_change_password, verification_token_expires_at, locked_at, deletion_requested_at, deletion_restore_token_hash, username_changed_at, email_otp_mfa_enabled, totp_secret_encrypted, totp_enabled_at, totp_last_used_step, phone_number, phone_verified_at, phone_mfa_enabled, status, username_skeleton, mfa_failed_attempts FROM users
+WHERE status = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListUsersByStatus(ctx context.Context, status string) ([]User, error) {
+	rows, err := q.query(ctx, q.listUsersByStatusStmt, listUsersByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerified,
+			&i.VerificationToken,
+			&i.Role,
+			&i.PasswordPolicyVersion,
+			&i.PasswordChangedAt,
+			&i.MustChangePassword,
+			&i.VerificationTokenExpiresAt,
+			&i.LockedAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionRestoreTokenHash,
+			&i.UsernameChangedAt,
+			&i.EmailOtpMfaEnabled,
+			&i.TotpSecretEncrypted,
+			&i.TotpEnabledAt,
+			&i.TotpLastUsedStep,
+			&i.PhoneNumber,
+			&i.PhoneVerifiedAt,
+			&i.PhoneMfaEnabled,
+			&i.Status,
+			&i.UsernameSkeleton,
+			&i.MfaFailedAttempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersDueForPurge = `-- name: ListUsersDueForPurge :many
+SELECT id FROM users
+WHERE deletion_requested_at < $1
+`
+
+func (q *Queries) ListUsersDueForPurge(ctx context.Context, deletionRequestedAt sql.NullTime) ([]uuid.UUID, error) {
+	rows, err := q.query(ctx, q.listUsersDueForPurgeStmt, listUsersDueForPurge, deletionRequestedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersWithoutSkeleton = `-- name: ListUsersWithoutSkeleton :many
+SELECT id, username FROM users
+WHERE username_skeleton = ''
+`
+
+type ListUsersWithoutSkeletonRow struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+func (q *Queries) ListUsersWithoutSkeleton(ctx context.Context) ([]ListUsersWithoutSkeletonRow, error) {
+	rows, err := q.query(ctx, q.listUsersWithoutSkeletonStmt, listUsersWithoutSkeleton)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersWithoutSkeletonRow
+	for rows.Next() {
+		var i ListUsersWithoutSkeletonRow
+		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUser = `-- name: LockUser :exec
+UPDATE users
+SET locked_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.lockUserStmt, lockUser, id)
+	return err
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :exec
+UPDATE users
+SET email_verified = TRUE,
+    verification_token = NULL,
+    verification_token_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.markEmailVerifiedStmt, markEmailVerified, id)
+	return err
+}
+
+const markPhoneVerified = `-- name: MarkPhoneVerified :exec
+UPDATE users
+SET phone_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND phone_number IS NOT NULL
+`
+
+func (q *Queries) MarkPhoneVerified(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.markPhoneVerifiedStmt, markPhoneVerified, id)
+	return err
+}
+
+const requestAccountDeletion = `-- name: RequestAccountDeletion :exec
+UPDATE users
+SET deletion_requested_at = NOW(), deletion_restore_token_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type RequestAccountDeletionParams struct {
+	ID                       uuid.UUID      `json:"id"`
+	DeletionRestoreTokenHash sql.NullString `json:"deletion_restore_token_hash"`
+}
+
+func (q *Queries) RequestAccountDeletion(ctx context.Context, arg RequestAccountDeletionParams) error {
+	_, err := q.exec(ctx, q.requestAccountDeletionStmt, requestAccountDeletion, arg.ID, arg.DeletionRestoreTokenHash)
+	return err
+}
+
+const resetMFAFailedAttempts = `-- name: ResetMFAFailedAttempts :exec
+UPDATE users
+SET mfa_failed_attempts = 0
+WHERE id = $1 AND mfa_failed_attempts <> 0
+`
+
+func (q *Queries) ResetMFAFailedAttempts(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.resetMFAFailedAttemptsStmt, resetMFAFailedAttempts, id)
+	return err
+}
+
+const restoreAccount = `-- name: RestoreAccount :exec
+UPDATE users
+SET deletion_requested_at = NULL, deletion_restore_token_hash = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RestoreAccount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.restoreAccountStmt, restoreAccount, id)
+	return err
+}
+
+const restoreAccountByToken = `-- name: RestoreAccountByToken :one
+UPDATE users
+SET deletion_requested_at = NULL, deletion_restore_token_hash = NULL, updated_at = NOW()
+WHERE deletion_restore_token_hash = $1
+AND deletion_requested_at > $2
+RETURNING id
+`
+
+type RestoreAccountByTokenParams struct {
+	DeletionRestoreTokenHash sql.NullString `json:"deletion_restore_token_hash"`
+	DeletionRequestedAt      sql.NullTime   `json:"deletion_requested_at"`
+}
+
+func (q *Queries) RestoreAccountByToken(ctx context.Context, arg RestoreAccountByTokenParams) (uuid.UUID, error) {
+	row := q.queryRow(ctx, q.restoreAccountByTokenStmt, restoreAccountByToken, arg.DeletionRestoreTokenHash, arg.DeletionRequestedAt)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const setEmailOTPMFAEnabled = `-- name: SetEmailOTPMFAEnabled :exec
+UPDATE users
+SET email_otp_mfa_enabled = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetEmailOTPMFAEnabledParams struct {
+	ID                 uuid.UUID `json:"id"`
+	EmailOtpMfaEnabled bool      `json:"email_otp_mfa_enabled"`
+}
+
+func (q *Queries) SetEmailOTPMFAEnabled(ctx context.Context, arg SetEmailOTPMFAEnabledParams) error {
+	_, err := q.exec(ctx, q.setEmailOTPMFAEnabledStmt, setEmailOTPMFAEnabled, arg.ID, arg.EmailOtpMfaEnabled)
+	return err
+}
+
+const setMustChangePassword = `-- name: SetMustChangePassword :exec
+UPDATE users
+SET must_change_password = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetMustChangePasswordParams struct {
+	ID                 uuid.UUID `json:"id"`
+	MustChangePassword bool      `json:"must_change_password"`
+}
+
+func (q *Queries) SetMustChangePassword(ctx context.Context, arg SetMustChangePasswordParams) error {
+	_, err := q.exec(ctx, q.setMustChangePasswordStmt, setMustChangePassword, arg.ID, arg.MustChangePassword)
+	return err
+}
+
+const setPendingTOTPSecret = `-- name: SetPendingTOTPSecret :exec
+UPDATE users
+SET totp_secret_encrypted = $2, totp_enabled_at = NULL, totp_last_used_step = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPendingTOTPSecretParams struct {
+	ID                  uuid.UUID      `json:"id"`
+	TotpSecretEncrypted sql.NullString `json:"totp_secret_encrypted"`
+}
+
+func (q *Queries) SetPendingTOTPSecret(ctx context.Context, arg SetPendingTOTPSecretParams) error {
+	_, err := q.exec(ctx, q.setPendingTOTPSecretStmt, setPendingTOTPSecret, arg.ID, arg.TotpSecretEncrypted)
+	return err
+}
+
+const setPhoneMFAEnabled = `-- name: SetPhoneMFAEnabled :exec
+UPDATE users
+SET phone_mfa_enabled = $2, updated_at = NOW()
+WHERE id = $1 AND phone_verified_at IS NOT NULL
+`
+
+type SetPhoneMFAEnabledParams struct {
+	ID              uuid.UUID `json:"id"`
+	PhoneMfaEnabled bool      `json:"phone_mfa_enabled"`
+}
+
+func (q *Queries) SetPhoneMFAEnabled(ctx context.Context, arg SetPhoneMFAEnabledParams) error {
+	_, err := q.exec(ctx, q.setPhoneMFAEnabledStmt, setPhoneMFAEnabled, arg.ID, arg.PhoneMfaEnabled)
+	return err
+}
+
+const setPhoneNumber = `-- name: SetPhoneNumber :exec
+UPDATE users
+SET phone_number = $2, phone_verified_at = NULL, phone_mfa_enabled = FALSE, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPhoneNumberParams struct {
+	ID          uuid.UUID      `json:"id"`
+	PhoneNumber sql.NullString `json:"phone_number"`
+}
+
+func (q *Queries) SetPhoneNumber(ctx context.Context, arg SetPhoneNumberParams) error {
+	_, err := q.exec(ctx, q.setPhoneNumberStmt, setPhoneNumber, arg.ID, arg.PhoneNumber)
+	return err
+}
+
+const setUsernameSkeleton = `-- name: SetUsernameSkeleton :exec
+UPDATE users
+SET username_skeleton = $2
+WHERE id = $1
+`
+
+type SetUsernameSkeletonParams struct {
+	ID               uuid.UUID `json:"id"`
+	UsernameSkeleton string    `json:"username_skeleton"`
+}
+
+func (q *Queries) SetUsernameSkeleton(ctx context.Context, arg SetUsernameSkeletonParams) error {
+	_, err := q.exec(ctx, q.setUsernameSkeletonStmt, setUsernameSkeleton, arg.ID, arg.UsernameSkeleton)
+	return err
+}
+
+const setVerificationToken = `-- name: SetVerificationToken :exec
+UPDATE users
+SET verification_token = $2, verification_token_expires_at = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetVerificationTokenParams struct {
+	ID                         uuid.UUID      `json:"id"`
+	VerificationToken          sql.NullString `json:"verification_token"`
+	VerificationTokenExpiresAt sql.NullTime   `json:"verification_token_expires_at"`
+}
+
+func (q *Queries) SetVerificationToken(ctx context.Context, arg SetVerificationTokenParams) error {
+	_, err := q.exec(ctx, q.setVerificationTokenStmt, setVerificationToken, arg.ID, arg.VerificationToken, arg.VerificationTokenExpiresAt)
+	return err
+}
+
+const unlockUser = `-- name: UnlockUser :exec
+UPDATE users
+SET locked_at = NULL, mfa_failed_attempts = 0, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UnlockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.unlockUserStmt, unlockUser, id)
+	return err
+}
+
+const updatePasswordHash = `-- name: UpdatePasswordHash :exec
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdatePasswordHashParams struct {
+	ID           uuid.UUID `json:"id"`
+	PasswordHash string    `json:"password_hash"`
+}
+
+func (q *Queries) UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) error {
+	_, err := q.exec(ctx, q.updatePasswordHashStmt, updatePasswordHash, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const updatePasswordPolicyVersion = `-- name: UpdatePasswordPolicyVersion :exec
+UPDATE users
+SET password_policy_version = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdatePasswordPolicyVersionParams struct {
+	ID                    uuid.UUID `json:"id"`
+	PasswordPolicyVersion int32     `json:"password_policy_version"`
+}
+
+func (q *Queries) UpdatePasswordPolicyVersion(ctx context.Context, arg UpdatePasswordPolicyVersionParams) error {
+	_, err := q.exec(ctx, q.updatePasswordPolicyVersionStmt, updatePasswordPolicyVersion, arg.ID, arg.PasswordPolicyVersion)
+	return err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :exec
+UPDATE users
+SET email = $2, email_verified = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserEmailParams struct {
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) error {
+	_, err := q.exec(ctx, q.updateUserEmailStmt, updateUserEmail, arg.ID, arg.Email, arg.EmailVerified)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    password_policy_version = $3,
+    password_changed_at = NOW(),
+    must_change_password = FALSE,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID                    uuid.UUID `json:"id"`
+	PasswordHash          string    `json:"password_hash"`
+	PasswordPolicyVersion int32     `json:"password_policy_version"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.exec(ctx, q.updateUserPasswordStmt, updateUserPassword, arg.ID, arg.PasswordHash, arg.PasswordPolicyVersion)
+	return err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :execrows
+UPDATE users
+SET status = $1, updated_at = NOW()
+WHERE id = $2 AND status = $3
+`
+
+type UpdateUserStatusParams struct {
+	Status     string    `json:"status"`
+	ID         uuid.UUID `json:"id"`
+	FromStatus string    `json:"from_status"`
+}
+
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (int64, error) {
+	result, err := q.exec(ctx, q.updateUserStatusStmt, updateUserStatus, arg.Status, arg.ID, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :execrows
+UPDATE users
+SET totp_last_used_step = $2
+WHERE id = $1 AND (totp_last_used_step IS NULL OR totp_last_used_step < $2)
+`
+
+type UseTOTPStepParams struct {
+	ID               uuid.UUID     `json:"id"`
+	TotpLastUsedStep sql.NullInt64 `json:"totp_last_used_step"`
+}
+
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error) {
+	result, err := q.exec(ctx, q.useTOTPStepStmt, useTOTPStep, arg.ID, arg.TotpLastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
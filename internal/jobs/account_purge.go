@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/storage"
+)
+
+// AccountPurger permanently removes accounts whose deletion grace period has
+// passed, along with their uploaded files.
+type AccountPurger struct {
+	database    *sql.DB
+	queries     *db.Queries
+	files       storage.Storage
+	gracePeriod time.Duration
+}
+
+func NewAccountPurger(database *sql.DB, queries *db.Queries, files storage.Storage, gracePeriod time.Duration) *AccountPurger {
+	return &AccountPurger{
+		database:    database,
+		queries:     queries,
+		files:       files,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Run purges once per interval until ctx is cancelled.
+func (p *AccountPurger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := p.Purge(ctx); err != nil {
+			log.Printf("Account purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted accounts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes every account past its grace period and returns how many
+// were removed.
+func (p *AccountPurger) Purge(ctx context.Context) (int, error) {
+	cutoff := sql.NullTime{Time: time.Now().Add(-p.gracePeriod), Valid: true}
+	userIDs, err := p.queries.ListUsersDueForPurge(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts due for purge: %w", err)
+	}
+
+	purged := 0
+	for _, userID := range userIDs {
+		if err := p.purgeUser(ctx, userID); err != nil {
+			return purged, fmt.Errorf("failed to purge account %s: %w", userID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purgeUser strips identifying details from the user's audit trail and
+// deletes the row; everything else owned by the user goes with it through
+// ON DELETE CASCADE. Files the rows pointed to are removed afterwards, as
+// nothing would reference them any more.
+func (p *AccountPurger) purgeUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := p.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := p.queries.WithTx(tx)
+	exportFiles, err := queries.ListUserDataExportFiles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := queries.AnonymizeAuditEvents(ctx, uuid.NullUUID{UUID: userID, Valid: true}); err != nil {
+		return err
+	}
+	if err := queries.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// The account is gone either way; leftover files are only logged
+	if err := p.files.DeletePrefix(ctx, "avatars/"+userID.String()); err != nil {
+		log.Printf("Failed to delete avatars of purged account %s: %v", userID, err)
+	}
+	for _, path := range exportFiles {
+		if err := os.Remove(path.String); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to delete data export of purged account %s: %v", userID, err)
+		}
+	}
+	return nil
+}
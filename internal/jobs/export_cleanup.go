@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+)
+
+// ExportCleaner removes data exports whose download link has expired.
+type ExportCleaner struct {
+	queries *db.Queries
+}
+
+func NewExportCleaner(queries *db.Queries) *ExportCleaner {
+	return &ExportCleaner{queries: queries}
+}
+
+// Run cleans once per interval until ctx is cancelled.
+func (c *ExportCleaner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := c.Clean(ctx); err != nil {
+			log.Printf("Data export cleanup failed: %v", err)
+		} else if removed > 0 {
+			log.Printf("Removed %d expired data exports", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Clean deletes every expired export file and its record and returns how
+// many were removed.
+func (c *ExportCleaner) Clean(ctx context.Context) (int, error) {
+	exports, err := c.queries.ListExpiredDataExports(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired data exports: %w", err)
+	}
+
+	removed := 0
+	for _, export := range exports {
+		if export.FilePath.Valid {
+			if err := os.Remove(export.FilePath.String); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return removed, fmt.Errorf("failed to remove data export %s: %w", export.ID, err)
+			}
+		}
+		if err := c.queries.DeleteDataExport(ctx, export.ID); err != nil {
+			return removed, fmt.Errorf("failed to delete data export %s: %w", export.ID, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
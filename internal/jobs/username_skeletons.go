@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	db "github.com/yeboahd24/authentication/internal/db/sqlc"
+	"github.com/yeboahd24/authentication/internal/service"
+)
+
+// BackfillUsernameSkeletons stores the confusable skeleton of every username
+// that does not have one yet, which after the migration that introduced them
+// is every existing account. Existing usernames that look alike are logged
+// for an admin to review; they are left as they are.
+func BackfillUsernameSkeletons(ctx context.Context, queries *db.Queries) error {
+	users, err := queries.ListUsersWithoutSkeleton(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list usernames without a skeleton: %w", err)
+	}
+
+	seen := make(map[string]string, len(users))
+	for _, user := range users {
+		skeleton := service.UsernameSkeleton(user.Username)
+		if other, ok := seen[skeleton]; ok {
+			log.Printf("Usernames %q and %q look alike", other, user.Username)
+		}
+		seen[skeleton] = user.Username
+
+		if err := queries.SetUsernameSkeleton(ctx, db.SetUsernameSkeletonParams{
+			ID:               user.ID,
+			UsernameSkeleton: skeleton,
+		}); err != nil {
+			return fmt.Errorf("failed to store username skeleton: %w", err)
+		}
+	}
+
+	if len(users) > 0 {
+		log.Printf("Stored username skeletons for %d accounts", len(users))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Package problem describes failed requests as RFC 7807 problem details, so
+// every error the service returns has the same machine-readable shape.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ContentType is the media type of problem details documents.
+const ContentType = "application/problem+json"
+
+// TypePrefix is prepended to a code to build the problem type URI. The
+// server documents each type at that path.
+const TypePrefix = "/problems/"
+
+// Error codes. A code names what went wrong independently of the wording of
+// the detail message, so clients should branch on it rather than on the
+// text. Codes never change once published.
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeInvalidCode          = "invalid_code"
+	CodeInvalidToken         = "invalid_token"
+	CodePasskeyFailed        = "passkey_failed"
+	CodeReauthRequired       = "reauth_required"
+	CodeMFARequired          = "mfa_required"
+	CodePasswordChangeNeeded = "password_change_required"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeConcurrentUpdate     = "concurrent_update"
+	CodeInvalidState         = "invalid_state"
+	CodeEmailTaken           = "email_taken"
+	CodeUsernameTaken        = "username_taken"
+	CodeUsernameConfusable   = "username_confusable"
+	CodeRegistrationClosed   = "registration_closed"
+	CodeInviteInvalid        = "invite_invalid"
+	CodeAccountPending       = "account_pending_approval"
+	CodeAccountRejected      = "account_rejected"
+	CodeAccountSuspended     = "account_suspended"
+	CodeAccountDeleted       = "account_pending_deletion"
+	CodeAccountLocked        = "account_locked"
+	CodeEmailNotVerified     = "email_not_verified"
+	CodeMethodNotEnabled     = "method_not_enabled"
+	CodeMFAUnavailable       = "mfa_unavailable"
+	CodeRateLimited          = "rate_limited"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeUnavailable          = "unavailable"
+	CodeInternal             = "internal_error"
+)
+
+// titles holds the summary of each problem type. Unlike the detail, a title
+// is the same every time its code is used.
+var titles = map[string]string{
+	CodeInvalidRequest:       "The request is malformed",
+	CodeValidationFailed:     "A value does not meet the requirements",
+	CodeUnauthorized:         "Authentication is required",
+	CodeInvalidCredentials:   "The credentials are incorrect",
+	CodeInvalidCode:          "The code is invalid or has expired",
+	CodeInvalidToken:         "The link or token is invalid or has expired",
+	CodePasskeyFailed:        "The passkey could not be verified",
+	CodeReauthRequired:       "A more recent authentication is required",
+	CodeMFARequired:          "A second factor is required",
+	CodePasswordChangeNeeded: "The password has to be changed first",
+	CodeForbidden:            "The request is not allowed",
+	CodeNotFound:             "The resource does not exist",
+	CodeConflict:             "The request conflicts with the current state of the resource",
+	CodeConcurrentUpdate:     "The request conflicted with a concurrent update",
+	CodeInvalidState:         "The resource is not in a state that allows this",
+	CodeEmailTaken:           "The email address is already registered",
+	CodeUsernameTaken:        "The username is already taken",
+	CodeUsernameConfusable:   "The username looks like an existing one",
+	CodeRegistrationClosed:   "Registration is closed",
+	CodeInviteInvalid:        "The invite cannot be used",
+	CodeAccountPending:       "The account is awaiting approval",
+	CodeAccountRejected:      "The account was not approved",
+	CodeAccountSuspended:     "The account is suspended",
+	CodeAccountDeleted:       "The account is scheduled for deletion",
+	CodeAccountLocked:        "The account is locked",
+	CodeEmailNotVerified:     "The email address is not verified",
+	CodeMethodNotEnabled:     "The method is not enabled",
+	CodeMFAUnavailable:       "No other second factor is available",
+	CodeRateLimited:          "Too many requests",
+	CodePayloadTooLarge:      "The request body is too large",
+	CodeUnsupportedMediaType: "The media type is not supported",
+	CodeUnavailable:          "The feature is not available",
+	CodeInternal:             "Internal server error",
+}
+
+// Title returns the summary of the problem type named by code, falling back
+// to the status text for codes without one.
+func Title(code string, status int) string {
+	if title, ok := titles[code]; ok {
+		return title
+	}
+	return http.StatusText(status)
+}
+
+// Known reports whether code is one of the codes above.
+func Known(code string) bool {
+	_, ok := titles[code]
+	return ok
+}
+
+// Details is a problem details object. Extensions are written as additional
+// top-level members, as RFC 7807 allows.
+type Details struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Code       string
+	RequestID  string
+	Extensions map[string]interface{}
+}
+
+// New describes a failed request. detail explains this occurrence to the
+// user; the title is derived from code.
+func New(r *http.Request, status int, code, detail string) *Details {
+	return &Details{
+		Type:      TypePrefix + code,
+		Title:     Title(code, status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+// With adds an extension member and returns d.
+func (d *Details) With(key string, value interface{}) *Details {
+	if d.Extensions == nil {
+		d.Extensions = make(map[string]interface{})
+	}
+	d.Extensions[key] = value
+	return d
+}
+
+func (d *Details) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(d.Extensions)+7)
+	for key, value := range d.Extensions {
+		members[key] = value
+	}
+	members["type"] = d.Type
+	members["title"] = d.Title
+	members["status"] = d.Status
+	members["code"] = d.Code
+	if d.Detail != "" {
+		members["detail"] = d.Detail
+	}
+	if d.Instance != "" {
+		members["instance"] = d.Instance
+	}
+	if d.RequestID != "" {
+		members["request_id"] = d.RequestID
+	}
+	return json.Marshal(members)
+}
+
+// Write sends d as an application/problem+json response.
+func Write(w http.ResponseWriter, d *Details) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(d.Status)
+	json.NewEncoder(w).Encode(d)
+}
+
+// Error writes a problem without extensions. It is a drop-in replacement for
+// http.Error in places that have no HTML variant, such as middleware for API
+// routes.
+func Error(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	Write(w, New(r, status, code, detail))
+}
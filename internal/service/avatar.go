@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrAvatarTooLarge    = errors.New("image is too large")
+	ErrAvatarType        = errors.New("image must be a JPEG, PNG or GIF")
+	ErrAvatarDimensions  = errors.New("image dimensions are too large")
+	ErrAvatarUndecodable = errors.New("image could not be decoded")
+)
+
+var allowedAvatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Defaults used when AvatarConfig leaves a field unset. A decoded image
+// takes up to four bytes per pixel, so the pixel limit caps the memory one
+// upload can use at about 48 MB.
+const (
+	defaultAvatarMaxBytes  = 5 << 20
+	defaultAvatarMaxPixels = 12_000_000
+)
+
+var defaultAvatarSizes = []int{64, 128, 256}
+
+type AvatarConfig struct {
+	MaxBytes  int64
+	MaxPixels int
+	Sizes     []int
+}
+
+// AvatarProcessor turns an uploaded image into square PNG thumbnails.
+// Decoding and re-encoding drops all metadata, including EXIF; the EXIF
+// orientation is applied first so photos taken sideways come out upright.
+type AvatarProcessor struct {
+	maxBytes  int64
+	maxPixels int
+	sizes     []int
+}
+
+func NewAvatarProcessor(config AvatarConfig) *AvatarProcessor {
+	p := &AvatarProcessor{
+		maxBytes:  config.MaxBytes,
+		maxPixels: config.MaxPixels,
+		sizes:     config.Sizes,
+	}
+	if p.maxBytes <= 0 {
+		p.maxBytes = defaultAvatarMaxBytes
+	}
+	if p.maxPixels <= 0 {
+		p.maxPixels = defaultAvatarMaxPixels
+	}
+	if len(p.sizes) == 0 {
+		p.sizes = defaultAvatarSizes
+	}
+	return p
+}
+
+func (p *AvatarProcessor) MaxBytes() int64 {
+	return p.maxBytes
+}
+
+func (p *AvatarProcessor) Sizes() []int {
+	return p.sizes
+}
+
+// HasSize reports whether size is one of the thumbnail sizes produced.
+func (p *AvatarProcessor) HasSize(size int) bool {
+	for _, s := range p.sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Process reads an image from r and returns a PNG thumbnail for every
+// configured size. The type is sniffed from the content rather than trusted
+// from the client, and the dimensions are checked before the image is
+// decoded so a small file cannot expand into a huge bitmap.
+func (p *AvatarProcessor) Process(r io.Reader) (map[int][]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, p.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > p.maxBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	if !allowedAvatarTypes[http.DetectContentType(data)] {
+		return nil, ErrAvatarType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarUndecodable
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > p.maxPixels {
+		return nil, ErrAvatarDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarUndecodable
+	}
+	square := squareBounds(img.Bounds())
+	orientation := exifOrientation(data)
+
+	thumbnails := make(map[int][]byte, len(p.sizes))
+	for _, size := range p.sizes {
+		// The crop is centred, so turning the small thumbnail gives the
+		// same result as turning the whole image first
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, orient(resize(img, square, size), orientation)); err != nil {
+			return nil, fmt.Errorf("failed to encode %dpx thumbnail: %w", size, err)
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// squareBounds returns the largest centred square within b.
+func squareBounds(b image.Rectangle) image.Rectangle {
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// resize scales the src region of img to a size×size image.
+func resize(img image.Image, src image.Rectangle, size int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
@@ -3,6 +3,8 @@ package service
 import (
     "fmt"
     "net/smtp"
+    "net/url"
+    "time"
 )
 
 type EmailConfig struct {
@@ -11,6 +13,7 @@ type EmailConfig struct {
     Username string
     Password string
     From     string
+    BaseURL  string
 }
 
 type EmailService struct {
@@ -23,9 +26,143 @@ func NewEmailService(config EmailConfig) *EmailService {
 
 func (s *EmailService) SendVerificationEmail(to, token string) error {
     subject := "Verify Your Email"
-    verificationLink := fmt.Sprintf("http://your-domain.com/verify?token=%s", token)
+    verificationLink := s.link("/verify", token)
     body := fmt.Sprintf("Please click the link below to verify your email:\n%s", verificationLink)
 
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendPasswordResetEmail(to, token string, validFor time.Duration) error {
+    subject := "Reset Your Password"
+    resetLink := s.link("/reset-password", token)
+    body := fmt.Sprintf("We received a request to reset your password. "+
+        "Click the link below to choose a new one. The link expires in %d minutes.\n%s\n\n"+
+        "If you did not request this, you can ignore this email.", int(validFor.Minutes()), resetLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendPasswordChangedEmail(to string) error {
+    subject := "Your Password Was Changed"
+    body := fmt.Sprintf("The password for your account was just changed.\n\n"+
+        "If you did not make this change, reset your password right away:\n%s/reset-password", s.config.BaseURL)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendEmailChangeConfirmation(to, token string, validFor time.Duration) error {
+    subject := "Confirm Your New Email Address"
+    confirmLink := s.link("/email/confirm", token)
+    body := fmt.Sprintf("Someone asked to use this address for their account. "+
+        "Click the link below within %d hours to confirm the change:\n%s\n\n"+
+        "If this wasn't you, you can ignore this email.", int(validFor.Hours()), confirmLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendInvite(to, inviter, code string, validFor time.Duration) error {
+    subject := "You're Invited"
+    inviteLink := fmt.Sprintf("%s/register?invite=%s", s.config.BaseURL, url.QueryEscape(code))
+    body := fmt.Sprintf("%s has invited you to create an account. "+
+        "Click the link below within %d days to sign up:\n%s", inviter, int(validFor.Hours()/24), inviteLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendAccountApproved(to, username string) error {
+    subject := "Your Account Has Been Approved"
+    body := fmt.Sprintf("Hi %s,\n\nYour account has been approved. You can sign in now:\n%s/login", username, s.config.BaseURL)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendAccountRejected(to, username, reason string) error {
+    subject := "Your Account Request Was Not Approved"
+    body := fmt.Sprintf("Hi %s,\n\nYour request for an account was not approved.", username)
+    if reason != "" {
+        body += "\n\nReason: " + reason
+    }
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendAccountSuspended(to, username string) error {
+    subject := "Your Account Has Been Suspended"
+    body := fmt.Sprintf("Hi %s,\n\nYour account has been suspended and you have been signed out. "+
+        "Contact an administrator if you think this is a mistake.", username)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendAccountReactivated(to, username string) error {
+    subject := "Your Account Has Been Reactivated"
+    body := fmt.Sprintf("Hi %s,\n\nYour account has been reactivated. You can sign in again:\n%s/login", username, s.config.BaseURL)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendIdentifierVerification(to, token string, validFor time.Duration) error {
+    subject := "Confirm Your Additional Email Address"
+    confirmLink := s.link("/email/identifier/verify", token)
+    body := fmt.Sprintf("Someone asked to add this address to their account so they can sign in with it. "+
+        "Click the link below within %d hours to confirm:\n%s\n\n"+
+        "If this wasn't you, you can ignore this email.", int(validFor.Hours()), confirmLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendEmailChangedNotification(to, newEmail, revertToken string, validFor time.Duration) error {
+    subject := "Your Email Address Was Changed"
+    revertLink := s.link("/email/revert", revertToken)
+    body := fmt.Sprintf("The email address on your account was changed to %s.\n\n"+
+        "If this wasn't you, click the link below within %d hours. It restores this address "+
+        "and locks the account until you reset your password:\n%s", newEmail, int(validFor.Hours()), revertLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendAccountDeletionScheduled(to, restoreToken string, gracePeriod time.Duration) error {
+    subject := "Your Account Is Scheduled for Deletion"
+    restoreLink := s.link("/account/restore", restoreToken)
+    body := fmt.Sprintf("Your account will be permanently deleted in %d days.\n\n"+
+        "Changed your mind? Click the link below before then to restore it:\n%s", int(gracePeriod.Hours()/24), restoreLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendMagicLinkEmail(to, token string, validFor time.Duration) error {
+    subject := "Your Sign-In Link"
+    loginLink := s.link("/login/magic", token)
+    body := fmt.Sprintf("Click the link below to sign in. It works once, only in the browser "+
+        "you requested it from, and expires in %d minutes.\n%s\n\n"+
+        "If you did not request this, you can ignore this email.", int(validFor.Minutes()), loginLink)
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendLoginCode(to, code string, validFor time.Duration) error {
+    subject := "Your Sign-In Code"
+    body := fmt.Sprintf("Your sign-in code is %s. It expires in %d minutes.\n\n"+
+        "If you did not try to sign in, someone may know your password. Change it right away.", code, int(validFor.Minutes()))
+
+    return s.send(to, subject, body)
+}
+
+func (s *EmailService) SendDataExportReady(to, token string, validFor time.Duration) error {
+    subject := "Your Data Export Is Ready"
+    downloadLink := s.link("/api/me/export/download", token)
+    body := fmt.Sprintf("The export of your personal data is ready. "+
+        "Sign in and open the link below within %d hours to download it:\n%s", int(validFor.Hours()), downloadLink)
+
+    return s.send(to, subject, body)
+}
+
+// link builds an absolute URL to path on the application carrying token.
+func (s *EmailService) link(path, token string) string {
+    return fmt.Sprintf("%s%s?token=%s", s.config.BaseURL, path, url.QueryEscape(token))
+}
+
+func (s *EmailService) send(to, subject, body string) error {
     msg := fmt.Sprintf("From: %s\r\n"+
         "To: %s\r\n"+
         "Subject: %s\r\n"+
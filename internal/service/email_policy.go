@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed disposable_domains.txt
+var bundledDisposableDomains string
+
+type EmailPolicyConfig struct {
+	AllowedDomains        []string
+	BlockedDomains        []string
+	BlockDisposable       bool
+	DisposableDomainsFile string
+	CheckMX               bool
+	MXTimeout             time.Duration
+}
+
+// MXResolver looks up the mail servers of a domain, and its addresses for
+// domains without any. *net.Resolver satisfies it; tests can pass a stub
+// instead.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// EmailPolicy decides which addresses may be used for an account. Domain
+// lists match the domain itself and any of its subdomains.
+type EmailPolicy struct {
+	config     EmailPolicyConfig
+	allowed    map[string]bool
+	blocked    map[string]bool
+	disposable map[string]bool
+	resolver   MXResolver
+}
+
+// NewEmailPolicy builds a policy from config. The bundled list of disposable
+// domains is used unless DisposableDomainsFile names a replacement. resolver
+// is only consulted when CheckMX is set.
+func NewEmailPolicy(config EmailPolicyConfig, resolver MXResolver) (*EmailPolicy, error) {
+	policy := &EmailPolicy{
+		config:   config,
+		allowed:  domainSet(config.AllowedDomains),
+		blocked:  domainSet(config.BlockedDomains),
+		resolver: resolver,
+	}
+
+	disposable, err := parseDomainList(strings.NewReader(bundledDisposableDomains))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundled disposable domains: %w", err)
+	}
+	if config.DisposableDomainsFile != "" {
+		file, err := os.Open(config.DisposableDomainsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load disposable domains: %w", err)
+		}
+		defer file.Close()
+
+		if disposable, err = parseDomainList(file); err != nil {
+			return nil, fmt.Errorf("failed to load disposable domains: %w", err)
+		}
+	}
+	policy.disposable = domainSet(disposable)
+
+	if config.CheckMX && resolver == nil {
+		return nil, errors.New("an MX resolver is required when check_mx is set")
+	}
+
+	return policy, nil
+}
+
+// Evaluate checks address against the policy and returns the violations
+// found, using the same shape as password policy violations. The MX lookup
+// only runs once every other rule has passed, and a lookup that fails for
+// any reason other than the domain having no mail servers lets the address
+// through, so a DNS outage does not stop people signing up.
+func (p *EmailPolicy) Evaluate(ctx context.Context, address string) []PolicyViolation {
+	violations := []PolicyViolation{}
+
+	if !ValidEmailSyntax(address) {
+		return append(violations, PolicyViolation{Rule: "syntax", Message: "Enter a valid email address"})
+	}
+
+	domain := EmailDomain(address)
+	if len(p.allowed) > 0 && !matchesDomain(p.allowed, domain) {
+		violations = append(violations, PolicyViolation{Rule: "domain_not_allowed", Message: "Email addresses at " + domain + " cannot be used to sign up"})
+	}
+	if matchesDomain(p.blocked, domain) {
+		violations = append(violations, PolicyViolation{Rule: "domain_blocked", Message: "Email addresses at " + domain + " are not allowed"})
+	}
+	if p.config.BlockDisposable && !matchesDomain(p.allowed, domain) && matchesDomain(p.disposable, domain) {
+		violations = append(violations, PolicyViolation{Rule: "disposable", Message: "Disposable email addresses are not allowed"})
+	}
+
+	if len(violations) == 0 && p.config.CheckMX && !p.acceptsMail(ctx, domain) {
+		violations = append(violations, PolicyViolation{Rule: "no_mx", Message: domain + " does not accept email"})
+	}
+
+	return violations
+}
+
+// acceptsMail reports whether domain can receive mail. A null MX record
+// (RFC 7505) means the domain explicitly accepts no mail. A domain without
+// MX records is still delivered to at its own address (RFC 5321 section
+// 5.1), so it passes when it has an A or AAAA record.
+func (p *EmailPolicy) acceptsMail(ctx context.Context, domain string) bool {
+	if p.config.MXTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.MXTimeout)
+		defer cancel()
+	}
+
+	records, err := p.resolver.LookupMX(ctx, domain)
+	if err != nil && !isNotFound(err) {
+		return true
+	}
+	if len(records) == 1 && records[0].Host == "." {
+		return false
+	}
+	if len(records) > 0 {
+		return true
+	}
+
+	hosts, err := p.resolver.LookupHost(ctx, domain)
+	if err != nil {
+		return !isNotFound(err)
+	}
+	return len(hosts) > 0
+}
+
+// isNotFound reports whether err is a lookup that found no records, as
+// opposed to one that failed.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// ValidEmailSyntax reports whether address is a bare RFC 5322 address with
+// a domain name, such as "jane@example.com". Display names, comments,
+// domain literals and single-label domains are rejected.
+func ValidEmailSyntax(address string) bool {
+	if len(address) > 254 {
+		return false
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return false
+	}
+
+	at := strings.LastIndex(address, "@")
+	local, domain := address[:at], address[at+1:]
+	if len(local) > 64 || !strings.Contains(domain, ".") {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		if strings.ContainsAny(label, "[]_ ") {
+			return false
+		}
+	}
+	return true
+}
+
+// EmailDomain returns the lowercased domain of address.
+func EmailDomain(address string) string {
+	return strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+}
+
+// matchesDomain reports whether domain or one of its parent domains is in
+// set.
+func matchesDomain(set map[string]bool, domain string) bool {
+	for domain != "" {
+		if set[domain] {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+func domainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		set[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	return set
+}
+
+func parseDomainList(r io.Reader) ([]string, error) {
+	var domains []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, strings.ToLower(line))
+	}
+	return domains, scanner.Err()
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubResolver answers lookups from fixed records. Domains without an entry
+// do not exist. When hang is set, lookups wait for the context to end, as a
+// resolver that never hears back would.
+type stubResolver struct {
+	mx    map[string][]*net.MX
+	hosts map[string][]string
+	err   error
+	hang  bool
+}
+
+func (r stubResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if err := r.wait(ctx, name); err != nil {
+		return nil, err
+	}
+	records, ok := r.mx[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func (r stubResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if err := r.wait(ctx, host); err != nil {
+		return nil, err
+	}
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func (r stubResolver) wait(ctx context.Context, name string) error {
+	if r.hang {
+		<-ctx.Done()
+		return &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+	}
+	return r.err
+}
+
+var testResolver = stubResolver{
+	mx: map[string][]*net.MX{
+		"example.com":  {{Host: "mx.example.com.", Pref: 10}},
+		"nomail.com":   {{Host: ".", Pref: 0}},
+		"implicit.com": {},
+	},
+	hosts: map[string][]string{
+		"implicit.com": {"192.0.2.1"},
+		"a-only.com":   {"2001:db8::1"},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   EmailPolicyConfig
+		resolver MXResolver
+		address  string
+		rules    []string
+	}{
+		{
+			name:    "valid address",
+			address: "jane@example.com",
+		},
+		{
+			name:    "bad syntax",
+			address: "jane@",
+			rules:   []string{"syntax"},
+		},
+		{
+			name:    "domain not in allow list",
+			config:  EmailPolicyConfig{AllowedDomains: []string{"corp.example"}},
+			address: "jane@example.com",
+			rules:   []string{"domain_not_allowed"},
+		},
+		{
+			name:    "subdomain of allowed domain",
+			config:  EmailPolicyConfig{AllowedDomains: []string{"example.com"}},
+			address: "jane@mail.example.com",
+		},
+		{
+			name:    "blocked domain",
+			config:  EmailPolicyConfig{BlockedDomains: []string{"Example.com"}},
+			address: "jane@EXAMPLE.com",
+			rules:   []string{"domain_blocked"},
+		},
+		{
+			name:    "disposable domain",
+			config:  EmailPolicyConfig{BlockDisposable: true},
+			address: "jane@mailinator.com",
+			rules:   []string{"disposable"},
+		},
+		{
+			name:    "allowed disposable domain",
+			config:  EmailPolicyConfig{BlockDisposable: true, AllowedDomains: []string{"mailinator.com"}},
+			address: "jane@mailinator.com",
+		},
+		{
+			name:     "mail servers",
+			config:   EmailPolicyConfig{CheckMX: true},
+			resolver: testResolver,
+			address:  "jane@example.com",
+		},
+		{
+			name:     "null MX",
+			config:   EmailPolicyConfig{CheckMX: true},
+			resolver: testResolver,
+			address:  "jane@nomail.com",
+			rules:    []string{"no_mx"},
+		},
+		{
+			name:     "NXDOMAIN",
+			config:   EmailPolicyConfig{CheckMX: true},
+			resolver: testResolver,
+			address:  "jane@missing.com",
+			rules:    []string{"no_mx"},
+		},
+		{
+			name:     "empty MX answer but an A record",
+			config:   EmailPolicyConfig{CheckMX: true},
+			resolver: testResolver,
+			address:  "jane@implicit.com",
+		},
+		{
+			name:     "no MX records but an AAAA record",
+			config:   EmailPolicyConfig{CheckMX: true},
+			resolver: testResolver,
+			address:  "jane@a-only.com",
+		},
+		{
+			name:     "lookup timeout",
+			config:   EmailPolicyConfig{CheckMX: true, MXTimeout: 10 * time.Millisecond},
+			resolver: stubResolver{hang: true},
+			address:  "jane@example.com",
+		},
+		{
+			name:     "lookup failure",
+			config:   EmailPolicyConfig{CheckMX: true},
+			resolver: stubResolver{err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}},
+			address:  "jane@example.com",
+		},
+		{
+			name:     "MX lookup skipped after another violation",
+			config:   EmailPolicyConfig{CheckMX: true, BlockedDomains: []string{"missing.com"}},
+			resolver: testResolver,
+			address:  "jane@missing.com",
+			rules:    []string{"domain_blocked"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewEmailPolicy(tt.config, tt.resolver)
+			if err != nil {
+				t.Fatalf("NewEmailPolicy: %v", err)
+			}
+
+			violations := policy.Evaluate(context.Background(), tt.address)
+			if len(violations) != len(tt.rules) {
+				t.Fatalf("Evaluate(%q) = %v, want rules %v", tt.address, violations, tt.rules)
+			}
+			for i, violation := range violations {
+				if violation.Rule != tt.rules[i] {
+					t.Errorf("Evaluate(%q) rule %d = %q, want %q", tt.address, i, violation.Rule, tt.rules[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewEmailPolicyRequiresResolver(t *testing.T) {
+	if _, err := NewEmailPolicy(EmailPolicyConfig{CheckMX: true}, nil); err == nil {
+		t.Fatal("NewEmailPolicy with CheckMX and no resolver succeeded")
+	}
+}
+
+func TestValidEmailSyntax(t *testing.T) {
+	tests := []struct {
+		address string
+		valid   bool
+	}{
+		{"jane@example.com", true},
+		{"jane.doe+tag@mail.example.co.uk", true},
+		{"j@x-y.example", true},
+		{"", false},
+		{"jane", false},
+		{"jane@", false},
+		{"@example.com", false},
+		{"jane@localhost", false},
+		{"Jane <jane@example.com>", false},
+		{"jane@[192.0.2.1]", false},
+		{"jane@example..com", false},
+		{"jane@-example.com", false},
+		{"jane@example-.com", false},
+		{"jane@exa_mple.com", false},
+		{" jane@example.com", false},
+		{"jane@example.com (Jane)", false},
+		{strings.Repeat("a", 65) + "@example.com", false},
+		{"a@" + strings.Repeat("a", 63) + ".com", true},
+		{"a@" + strings.Repeat("a", 64) + ".com", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidEmailSyntax(tt.address); got != tt.valid {
+			t.Errorf("ValidEmailSyntax(%q) = %v, want %v", tt.address, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// exifTagOrientation is the EXIF tag saying how the stored pixels have to be
+// turned to display the image upright.
+const exifTagOrientation = 0x0112
+
+// exifOrientation returns the EXIF orientation (1 to 8) of a JPEG, or 1 if
+// data is not a JPEG or carries no orientation.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the segments up to the start of the image data looking for the
+	// APP1 segment holding the EXIF block
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// structure, the format EXIF data is stored in.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifTagOrientation {
+			continue
+		}
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+		return orientation
+	}
+	return 1
+}
+
+// orient turns a square image upright according to an EXIF orientation.
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	n := img.Bounds().Dx()
+	last := n - 1
+	dst := image.NewNRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = last-x, y
+			case 3: // upside down
+				sx, sy = last-x, last-y
+			case 4: // mirrored upside down
+				sx, sy = x, last-y
+			case 5: // mirrored and turned left
+				sx, sy = y, x
+			case 6: // turned left, needs a quarter turn clockwise
+				sx, sy = y, last-x
+			case 7: // mirrored and turned right
+				sx, sy = last-y, last-x
+			case 8: // turned right, needs a quarter turn anticlockwise
+				sx, sy = last-y, x
+			}
+			dst.SetNRGBA(x, y, img.NRGBAAt(img.Rect.Min.X+sx, img.Rect.Min.Y+sy))
+		}
+	}
+	return dst
+}
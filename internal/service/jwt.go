@@ -13,17 +13,52 @@ func NewJWTMaker(secretKey string) *JWTMaker {
     return &JWTMaker{secretKey: secretKey}
 }
 
+// ScopePasswordChange marks a restricted token that may only be used to
+// change the password. Tokens without a scope grant full access.
+const ScopePasswordChange = "password_change"
+
+// ScopeMFAPending marks a restricted token issued after the first login
+// factor. It may only be used to complete the second factor.
+const ScopeMFAPending = "mfa_pending"
+
 type JWTClaims struct {
     UserID   string `json:"user_id"`
     Username string `json:"username"`
+    Scope    string `json:"scope,omitempty"`
+    // AuthTime is when the user last proved who they are, by logging in or
+    // re-authenticating. It is carried over when a token is reissued.
+    AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+    // AMR lists the methods the user authenticated with so far. A pending
+    // second factor token uses it to rule out repeating the first factor.
+    AMR []string `json:"amr,omitempty"`
     jwt.RegisteredClaims
 }
 
+// AuthenticatedAt returns AuthTime, or the zero time for tokens issued
+// before it was recorded.
+func (c *JWTClaims) AuthenticatedAt() time.Time {
+    if c.AuthTime == nil {
+        return time.Time{}
+    }
+    return c.AuthTime.Time
+}
+
 func (maker *JWTMaker) CreateToken(userID, username string, duration time.Duration) (string, error) {
+    return maker.CreateSessionToken("", userID, username, "", time.Now(), duration)
+}
+
+// CreateSessionToken creates a token bound to a server-side session, carried
+// as the jti claim so the session can be revoked. An empty scope grants full
+// access. amr records the authentication methods used.
+func (maker *JWTMaker) CreateSessionToken(sessionID, userID, username, scope string, authTime time.Time, duration time.Duration, amr ...string) (string, error) {
     claims := &JWTClaims{
         UserID:   userID,
         Username: username,
+        Scope:    scope,
+        AuthTime: jwt.NewNumericDate(authTime),
+        AMR:      amr,
         RegisteredClaims: jwt.RegisteredClaims{
+            ID:        sessionID,
             ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
         },
@@ -1,9 +1,13 @@
 package service
 
 import (
+    "crypto/hmac"
     "crypto/rand"
+    "crypto/sha256"
     "encoding/base64"
     "fmt"
+    "os"
+    "strconv"
     "strings"
 
     "golang.org/x/crypto/argon2"
@@ -14,6 +18,11 @@ type PasswordConfig struct {
     memory  uint32
     threads uint8
     keyLen  uint32
+
+    // pepperVersion is the key new hashes are peppered with; 0 disables the
+    // pepper. Older versions stay in pepperKeys so existing hashes verify.
+    pepperVersion int
+    pepperKeys    map[int][]byte
 }
 
 func NewPasswordConfig() *PasswordConfig {
@@ -25,20 +34,66 @@ func NewPasswordConfig() *PasswordConfig {
     }
 }
 
+// WithPepper returns a copy of the config that HMACs passwords with
+// keys[currentVersion] before hashing them.
+func (c *PasswordConfig) WithPepper(currentVersion int, keys map[int][]byte) (*PasswordConfig, error) {
+    if currentVersion != 0 {
+        if _, ok := keys[currentVersion]; !ok {
+            return nil, fmt.Errorf("no pepper key for current version %d", currentVersion)
+        }
+    }
+
+    peppered := *c
+    peppered.pepperVersion = currentVersion
+    peppered.pepperKeys = keys
+    return &peppered, nil
+}
+
+// LoadPepperKey reads a pepper key, or any other server-side secret key, from
+// file, or from the environment variable env when no file is given.
+func LoadPepperKey(file, env string) ([]byte, error) {
+    var key string
+    switch {
+    case file != "":
+        data, err := os.ReadFile(file)
+        if err != nil {
+            return nil, err
+        }
+        key = string(data)
+    case env != "":
+        key = os.Getenv(env)
+    }
+
+    key = strings.TrimSpace(key)
+    if key == "" {
+        return nil, fmt.Errorf("key is empty")
+    }
+    return []byte(key), nil
+}
+
 func (c *PasswordConfig) HashPassword(password string) (string, error) {
     salt := make([]byte, 16)
     if _, err := rand.Read(salt); err != nil {
         return "", err
     }
 
-    hash := argon2.IDKey([]byte(password), salt, c.time, c.memory, c.threads, c.keyLen)
+    input, err := c.pepper(password, c.pepperVersion)
+    if err != nil {
+        return "", err
+    }
+    hash := argon2.IDKey(input, salt, c.time, c.memory, c.threads, c.keyLen)
 
     // Format: $argon2id$v=19$m=65536,t=1,p=4$salt$hash
+    // Peppered hashes append the key version: $argon2id$v=19$m=65536,t=1,p=4,k=1$salt$hash
     b64Salt := base64.RawStdEncoding.EncodeToString(salt)
     b64Hash := base64.RawStdEncoding.EncodeToString(hash)
 
-    encodedHash := fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s",
-        c.memory, c.time, c.threads, b64Salt, b64Hash)
+    params := fmt.Sprintf("m=%d,t=%d,p=%d", c.memory, c.time, c.threads)
+    if c.pepperVersion != 0 {
+        params += fmt.Sprintf(",k=%d", c.pepperVersion)
+    }
+
+    encodedHash := fmt.Sprintf("$argon2id$v=19$%s$%s$%s", params, b64Salt, b64Hash)
 
     return encodedHash, nil
 }
@@ -49,6 +104,11 @@ func (c *PasswordConfig) VerifyPassword(password, encodedHash string) (bool, err
         return false, fmt.Errorf("invalid hash format")
     }
 
+    version, err := pepperVersion(parts[3])
+    if err != nil {
+        return false, err
+    }
+
     salt, err := base64.RawStdEncoding.DecodeString(parts[4])
     if err != nil {
         return false, err
@@ -59,6 +119,54 @@ func (c *PasswordConfig) VerifyPassword(password, encodedHash string) (bool, err
         return false, err
     }
 
-    newHash := argon2.IDKey([]byte(password), salt, c.time, c.memory, c.threads, uint32(len(hash)))
-    return string(hash) == string(newHash), nil
+    input, err := c.pepper(password, version)
+    if err != nil {
+        return false, err
+    }
+
+    newHash := argon2.IDKey(input, salt, c.time, c.memory, c.threads, uint32(len(hash)))
+    return hmac.Equal(hash, newHash), nil
+}
+
+// NeedsRehash reports whether encodedHash was peppered with a key other than
+// the current one, so it should be replaced after a successful login.
+func (c *PasswordConfig) NeedsRehash(encodedHash string) bool {
+    parts := strings.Split(encodedHash, "$")
+    if len(parts) != 6 {
+        return false
+    }
+
+    version, err := pepperVersion(parts[3])
+    return err == nil && version != c.pepperVersion
+}
+
+// pepper HMACs password with the key of the given version. Version 0 means
+// the hash was made without a pepper.
+func (c *PasswordConfig) pepper(password string, version int) ([]byte, error) {
+    if version == 0 {
+        return []byte(password), nil
+    }
+
+    key, ok := c.pepperKeys[version]
+    if !ok {
+        return nil, fmt.Errorf("unknown pepper version %d", version)
+    }
+
+    mac := hmac.New(sha256.New, key)
+    mac.Write([]byte(password))
+    return mac.Sum(nil), nil
+}
+
+// pepperVersion extracts k from the "m=..,t=..,p=..[,k=..]" hash parameters.
+func pepperVersion(params string) (int, error) {
+    for _, param := range strings.Split(params, ",") {
+        if value, ok := strings.CutPrefix(param, "k="); ok {
+            version, err := strconv.Atoi(value)
+            if err != nil {
+                return 0, fmt.Errorf("invalid pepper version: %w", err)
+            }
+            return version, nil
+        }
+    }
+    return 0, nil
 }
\ No newline at end of file
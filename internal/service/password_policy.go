@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	WeakPassword       = 0
+	MediumPassword     = 1
+	StrongPassword     = 2
+	VeryStrongPassword = 3
+)
+
+type PasswordPolicyConfig struct {
+	Version         int
+	MinLength       int
+	MaxLength       int
+	RequireUpper    bool
+	RequireLower    bool
+	RequireDigit    bool
+	RequireSpecial  bool
+	BannedWordsFile string
+	AllowUserInfo   bool
+	MinStrength     int
+	HistorySize     int
+	MaxAge          time.Duration
+}
+
+// PolicyViolation describes a single rule a password failed. Rule is a stable
+// identifier clients can key off, Message is meant for display.
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type PasswordPolicy struct {
+	config      PasswordPolicyConfig
+	bannedWords []string
+}
+
+func NewPasswordPolicy(config PasswordPolicyConfig) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{config: config}
+
+	if config.BannedWordsFile != "" {
+		words, err := loadBannedWords(config.BannedWordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load banned words: %w", err)
+		}
+		policy.bannedWords = words
+	}
+
+	return policy, nil
+}
+
+// Version returns the policy version passwords are checked against.
+func (p *PasswordPolicy) Version() int {
+	return p.config.Version
+}
+
+// HistorySize returns how many previous passwords a user may not reuse.
+func (p *PasswordPolicy) HistorySize() int {
+	return p.config.HistorySize
+}
+
+// Expired reports whether a password last changed at changedAt must be
+// rotated. A zero MaxAge disables expiry.
+func (p *PasswordPolicy) Expired(changedAt time.Time) bool {
+	return p.config.MaxAge > 0 && time.Since(changedAt) > p.config.MaxAge
+}
+
+// Evaluate checks password against every rule of the policy and returns the
+// violations found. userInputs are the username, email and any other values
+// the password must not contain when AllowUserInfo is false.
+func (p *PasswordPolicy) Evaluate(password string, userInputs ...string) []PolicyViolation {
+	violations := []PolicyViolation{}
+	length := utf8.RuneCountInString(password)
+
+	if p.config.MinLength > 0 && length < p.config.MinLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    "min_length",
+			Message: fmt.Sprintf("Password must be at least %d characters", p.config.MinLength),
+		})
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    "max_length",
+			Message: fmt.Sprintf("Password must be at most %d characters", p.config.MaxLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+	if p.config.RequireUpper && !hasUpper {
+		violations = append(violations, PolicyViolation{Rule: "require_upper", Message: "Password must contain an uppercase letter"})
+	}
+	if p.config.RequireLower && !hasLower {
+		violations = append(violations, PolicyViolation{Rule: "require_lower", Message: "Password must contain a lowercase letter"})
+	}
+	if p.config.RequireDigit && !hasDigit {
+		violations = append(violations, PolicyViolation{Rule: "require_digit", Message: "Password must contain a number"})
+	}
+	if p.config.RequireSpecial && !hasSpecial {
+		violations = append(violations, PolicyViolation{Rule: "require_special", Message: "Password must contain a special character"})
+	}
+
+	lowered := strings.ToLower(password)
+	for _, word := range p.bannedWords {
+		if strings.Contains(lowered, word) {
+			violations = append(violations, PolicyViolation{Rule: "banned_word", Message: "Password contains a commonly used word or sequence"})
+			break
+		}
+	}
+
+	if !p.config.AllowUserInfo {
+		for _, input := range userInputs {
+			if containsUserInfo(lowered, input) {
+				violations = append(violations, PolicyViolation{Rule: "user_info", Message: "Password must not contain your username or email"})
+				break
+			}
+		}
+	}
+
+	if EstimatePasswordStrength(password) < p.config.MinStrength {
+		violations = append(violations, PolicyViolation{Rule: "min_strength", Message: "Password is too easy to guess. Try a longer password with mixed characters"})
+	}
+
+	return violations
+}
+
+// containsUserInfo reports whether the lowercased password contains input or,
+// for email addresses, the local part of it. Fragments shorter than three
+// characters are ignored since they match too much by accident.
+func containsUserInfo(lowered, input string) bool {
+	input = strings.ToLower(strings.TrimSpace(input))
+	candidates := []string{input}
+	if at := strings.Index(input, "@"); at > 0 {
+		candidates = append(candidates, input[:at])
+	}
+
+	for _, candidate := range candidates {
+		if len(candidate) >= 3 && strings.Contains(lowered, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func loadBannedWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, strings.ToLower(line))
+	}
+	return words, scanner.Err()
+}
+
+// EstimatePasswordStrength scores a password from WeakPassword to
+// VeryStrongPassword based on its length and character variety.
+func EstimatePasswordStrength(password string) int {
+	// Initialize score
+	score := 0
+
+	// Check length
+	if len(password) >= 8 {
+		score++
+	}
+
+	// Check for numbers
+	hasNumber := false
+	for _, char := range password {
+		if char >= '0' && char <= '9' {
+			hasNumber = true
+			break
+		}
+	}
+	if hasNumber {
+		score++
+	}
+
+	// Check for special characters
+	hasSpecial := false
+	for _, char := range password {
+		if (char >= '!' && char <= '/') || (char >= ':' && char <= '@') || (char >= '[' && char <= '`') || (char >= '{' && char <= '~') {
+			hasSpecial = true
+			break
+		}
+	}
+	if hasSpecial {
+		score++
+	}
+
+	// Check for mixed case
+	hasUpper := false
+	hasLower := false
+	for _, char := range password {
+		if char >= 'A' && char <= 'Z' {
+			hasUpper = true
+		}
+		if char >= 'a' && char <= 'z' {
+			hasLower = true
+		}
+	}
+	if hasUpper && hasLower {
+		score++
+	}
+
+	return score
+}
+
+func PasswordFeedback(strength int) string {
+	switch strength {
+	case WeakPassword:
+		return "Password is too weak. Use at least 8 characters with numbers, special characters, and mixed case."
+	case MediumPassword:
+		return "Password could be stronger. Try adding special characters or mixed case."
+	case StrongPassword:
+		return "Good password strength."
+	case VeryStrongPassword:
+		return "Excellent password strength!"
+	default:
+		return "Invalid password strength"
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SMSMessage is a text to deliver to a phone number. When Voice is set the
+// message is read out in a phone call instead of sent as a text.
+type SMSMessage struct {
+	To    string
+	From  string
+	Body  string
+	Voice bool
+}
+
+// SMSSender delivers messages to phone numbers. Implementations wrap a
+// particular provider; SMSService decides what the messages say.
+type SMSSender interface {
+	Send(msg SMSMessage) error
+}
+
+// SMSService sends the application's phone messages through an SMSSender,
+// as EmailService does for email.
+type SMSService struct {
+	sender SMSSender
+	from   string
+}
+
+func NewSMSService(sender SMSSender, from string) *SMSService {
+	return &SMSService{sender: sender, from: from}
+}
+
+func (s *SMSService) SendVerificationCode(to, code string, validFor time.Duration) error {
+	body := fmt.Sprintf("Your phone verification code is %s. It expires in %d minutes.", code, int(validFor.Minutes()))
+
+	return s.sender.Send(SMSMessage{To: to, From: s.from, Body: body})
+}
+
+// SendLoginCode texts a second factor code, or reads it out in a call when
+// voice is set.
+func (s *SMSService) SendLoginCode(to, code string, validFor time.Duration, voice bool) error {
+	body := fmt.Sprintf("Your sign-in code is %s. It expires in %d minutes. "+
+		"If you did not try to sign in, change your password.", code, int(validFor.Minutes()))
+	if voice {
+		// Spaced digits are read one at a time rather than as a number
+		body = fmt.Sprintf("Your sign-in code is %s. Again, your code is %s.",
+			spellDigits(code), spellDigits(code))
+	}
+
+	return s.sender.Send(SMSMessage{To: to, From: s.from, Body: body, Voice: voice})
+}
+
+func spellDigits(code string) string {
+	return strings.Join(strings.Split(code, ""), " ")
+}
+
+// ErrInvalidPhoneNumber is returned for numbers that are not in
+// international format.
+var ErrInvalidPhoneNumber = errors.New("phone number must be in international format, e.g. +14155550123")
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NormalizePhoneNumber strips the spaces, dashes, dots and brackets people
+// type in phone numbers and checks the result is an E.164 number.
+func NormalizePhoneNumber(raw string) (string, error) {
+	number := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(raw))
+	if strings.HasPrefix(number, "00") {
+		number = "+" + number[2:]
+	}
+	if !e164Pattern.MatchString(number) {
+		return "", ErrInvalidPhoneNumber
+	}
+	return number, nil
+}
+
+// MaskPhoneNumber hides all but the last digits of number for display.
+func MaskPhoneNumber(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return strings.Repeat("•", len(number)-4) + number[len(number)-4:]
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSMSConfig points HTTPSMSSender at a gateway.
+type HTTPSMSConfig struct {
+	URL     string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// HTTPSMSSender posts each message as JSON to a gateway URL:
+//
+//	{"to": "+14155550123", "from": "...", "body": "...", "channel": "sms"}
+//
+// with the API key as a bearer token. channel is "voice" for calls. Most
+// providers can accept this directly or through a small relay, so no
+// provider SDK is needed.
+type HTTPSMSSender struct {
+	config HTTPSMSConfig
+	client *http.Client
+}
+
+func NewHTTPSMSSender(config HTTPSMSConfig) *HTTPSMSSender {
+	return &HTTPSMSSender{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (s *HTTPSMSSender) Send(msg SMSMessage) error {
+	channel := "sms"
+	if msg.Voice {
+		channel = "voice"
+	}
+	payload, err := json.Marshal(map[string]string{
+		"to":      msg.To,
+		"from":    msg.From,
+		"body":    msg.Body,
+		"channel": channel,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("SMS gateway returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// GenerateToken returns a random URL-safe token to hand to the user and the
+// hash to store in its place, so a database leak does not expose live tokens.
+func GenerateToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the value stored for token. Tokens carry 256 bits of
+// entropy, so a plain SHA-256 is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateCode returns a random numeric one-time code with the given number
+// of digits.
+func GenerateCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// HashCode returns the value stored for a one-time code issued to subject.
+// Short codes are easy to brute force from their hash, so binding the hash
+// to the subject only rules out precomputed tables; expiry and attempt
+// limits are what actually protect them.
+func HashCode(subject, code string) string {
+	return HashToken(subject + ":" + code)
+}
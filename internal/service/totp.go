@@ -0,0 +1,177 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// RFC 6238 parameters. These are the defaults every authenticator app
+// supports, so they are not configurable.
+const (
+	totpDigits     = 6
+	totpPeriod     = 30 * time.Second
+	totpSecretSize = 20
+	// totpSkew is how many periods either side of now are accepted, to
+	// allow for clock drift on the user's device.
+	totpSkew = 1
+)
+
+// totpEncoding is base32 without padding, as authenticator apps expect.
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP issues and checks time-based one-time passwords. Secrets are
+// encrypted with AES-GCM before they are stored.
+type TOTP struct {
+	issuer string
+	aead   cipher.AEAD
+}
+
+// NewTOTP returns a TOTP that names issuer in authenticator apps and
+// encrypts secrets with a key derived from key.
+func NewTOTP(issuer string, key []byte) (*TOTP, error) {
+	if len(key) == 0 {
+		return nil, errors.New("TOTP encryption key is empty")
+	}
+
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTP{issuer: issuer, aead: aead}, nil
+}
+
+// GenerateSecret returns a new base32 encoded secret.
+func (t *TOTP) GenerateSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// EncryptSecret returns the form of secret that is stored in the database.
+func (t *TOTP) EncryptSecret(secret string) (string, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := t.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret.
+func (t *TOTP) DecryptSecret(encrypted string) (string, error) {
+	sealed, err := base64.RawStdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < t.aead.NonceSize() {
+		return "", errors.New("encrypted TOTP secret is too short")
+	}
+
+	nonce, ciphertext := sealed[:t.aead.NonceSize()], sealed[t.aead.NonceSize():]
+	secret, err := t.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// URI returns the otpauth:// URI authenticator apps import secret from.
+func (t *TOTP) URI(account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", t.issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(t.issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Validate reports whether code is valid for secret at now. On success it
+// also returns the time step the code belongs to, so callers can refuse to
+// accept the same step twice.
+func (t *TOTP) Validate(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode computes the RFC 4226 HOTP value of key for counter step.
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// QRCodePNG renders content as a size by size pixel QR code.
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+// GenerateRecoveryCodes returns n random single-use recovery codes in the
+// form xxxxx-xxxxx.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the value stored for a recovery code. Case,
+// spaces and dashes are ignored so codes can be typed back however the
+// user wrote them down.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.NewReplacer("-", "", " ", "").Replace(normalized)
+	return HashToken(normalized)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type UsernamePolicyConfig struct {
+	MinLength          int
+	MaxLength          int
+	AllowUnicode       bool
+	AllowedPunctuation string
+}
+
+// UsernamePolicy decides what a username may look like. Usernames are
+// compared case-insensitively and after NFKC normalization, so callers
+// should pass them through Normalize before checking or storing them.
+type UsernamePolicy struct {
+	config UsernamePolicyConfig
+}
+
+func NewUsernamePolicy(config UsernamePolicyConfig) *UsernamePolicy {
+	return &UsernamePolicy{config: config}
+}
+
+// Normalize returns the NFKC form of username without surrounding spaces.
+// It folds compatibility characters such as fullwidth letters and ligatures
+// into their plain equivalents.
+func (p *UsernamePolicy) Normalize(username string) string {
+	return norm.NFKC.String(strings.TrimSpace(username))
+}
+
+// Evaluate checks a normalized username against every rule of the policy and
+// returns the violations found.
+func (p *UsernamePolicy) Evaluate(username string) []PolicyViolation {
+	violations := []PolicyViolation{}
+
+	length := utf8.RuneCountInString(username)
+	if length < p.config.MinLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    "min_length",
+			Message: fmt.Sprintf("Username must be at least %d characters", p.config.MinLength),
+		})
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    "max_length",
+			Message: fmt.Sprintf("Username must be at most %d characters", p.config.MaxLength),
+		})
+	}
+
+	allowed := "letters, numbers"
+	if p.config.AllowedPunctuation != "" {
+		allowed += " and " + strings.Join(strings.Split(p.config.AllowedPunctuation, ""), " ")
+	}
+	for _, r := range username {
+		if !p.allowedRune(r) {
+			violations = append(violations, PolicyViolation{Rule: "characters", Message: "Username can only contain " + allowed})
+			break
+		}
+	}
+	if first, _ := utf8.DecodeRuneInString(username); strings.ContainsRune(p.config.AllowedPunctuation, first) {
+		violations = append(violations, PolicyViolation{Rule: "leading_punctuation", Message: "Username must start with a letter or number"})
+	}
+	if p.config.AllowUnicode && mixesScripts(username) {
+		violations = append(violations, PolicyViolation{Rule: "mixed_script", Message: "Username cannot mix letters from different alphabets"})
+	}
+
+	return violations
+}
+
+func (p *UsernamePolicy) allowedRune(r rune) bool {
+	if strings.ContainsRune(p.config.AllowedPunctuation, r) {
+		return true
+	}
+	if r < utf8.RuneSelf {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+	}
+	return p.config.AllowUnicode && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r))
+}
+
+// mixesScripts reports whether the letters of username come from more than
+// one script, as in "pаypal" with a Cyrillic "а".
+func mixesScripts(username string) bool {
+	var seen *unicode.RangeTable
+	for _, r := range username {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		script := scriptOf(r)
+		if script == nil {
+			continue
+		}
+		if seen != nil && seen != script {
+			return true
+		}
+		seen = script
+	}
+	return false
+}
+
+func scriptOf(r rune) *unicode.RangeTable {
+	for name, table := range unicode.Scripts {
+		if name == "Common" || name == "Inherited" {
+			continue
+		}
+		if unicode.Is(table, r) {
+			return table
+		}
+	}
+	return nil
+}
+
+// UsernameSkeleton reduces username to a form in which look-alike names are
+// equal, following the skeleton algorithm of Unicode TR39: "paypal",
+// "PayPaI" and "раураl" written with Cyrillic letters all share a skeleton.
+// Usernames keep the case they were typed in, so a character is mapped as it
+// appears first, which catches a capital I posing as a lowercase l, and only
+// then folded and mapped again, which catches a Cyrillic letter in either
+// case. Diacritics are dropped as well. Two accounts should not have the
+// same skeleton.
+func UsernameSkeleton(username string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(norm.NFKC.String(username)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if replacement, ok := confusables[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		r = unicode.ToLower(r)
+		if replacement, ok := confusables[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	skeleton := b.String()
+	for _, pair := range confusableSequences {
+		skeleton = strings.ReplaceAll(skeleton, pair[0], pair[1])
+	}
+	return skeleton
+}
+
+// confusables maps characters to the lowercase Latin text they are easily
+// mistaken for. It is the part of the TR39 confusables table that covers the
+// letters and digits usernames are made of. Capitals are only listed when
+// they pass for something other than their lowercase form does.
+var confusables = map[rune]string{
+	// Latin and digits
+	'0': "o", '1': "l", 'I': "l", '|': "l", 'ı': "i", 'ȷ': "j", 'ɑ': "a", 'ɡ': "g", 'ɩ': "i", 'ʋ': "u",
+	// Cyrillic
+	'а': "a", 'В': "b", 'ь': "b", 'с': "c", 'ԁ': "d", 'е': "e", 'Н': "h", 'һ': "h", 'І': "l", 'і': "i",
+	'ӏ': "l", 'ј': "j", 'К': "k", 'М': "m", 'о': "o", 'р': "p", 'ԛ': "q", 'ѕ': "s", 'Т': "t", 'у': "y",
+	'ү': "y", 'ԝ': "w", 'х': "x",
+	// Greek
+	'α': "a", 'Β': "b", 'Ε': "e", 'Η': "h", 'Ι': "l", 'ι': "i", 'Κ': "k", 'κ': "k", 'Μ': "m", 'Ν': "n",
+	'ν': "v", 'ο': "o", 'ρ': "p", 'Τ': "t", 'Υ': "y", 'υ': "u", 'Χ': "x", 'γ': "y", 'Ζ': "z",
+}
+
+// confusableSequences are runs of characters that together look like another
+// letter. They are applied after the per-character mapping.
+var confusableSequences = [][2]string{
+	{"rn", "m"},
+	{"vv", "w"},
+}
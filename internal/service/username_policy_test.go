@@ -0,0 +1,40 @@
+package service
+
+import "testing"
+
+func TestUsernameSkeleton(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"capital I for l", "ADMlN", "ADMIN", true},
+		{"capital I for l in mixed case", "PaypaI", "paypal", true},
+		{"digit one for l", "paypa1", "paypal", true},
+		{"digit zero for o", "r0ot", "root", true},
+		{"Cyrillic lowercase", "раураl", "paypal", true},
+		{"Cyrillic capitals", "АDМIN", "ADMIN", true},
+		{"Cyrillic capitals without a lowercase look-alike", "ВОВ", "BOB", true},
+		{"Cyrillic capitals H T K", "НТК", "HTK", true},
+		{"Greek capitals", "ΚΑΤΕ", "KATE", true},
+		{"Greek capitals H M N T", "ΗΜΝΤ", "HMNT", true},
+		{"Greek capital iota for l", "ΑΡΡΙΕ", "APPlE", true},
+		{"letter case", "Alice", "alice", true},
+		{"diacritics", "café", "cafe", true},
+		{"full-width letters", "ＡＤＭＩＮ", "ADMIN", true},
+		{"rn for m", "rnike", "mike", true},
+		{"vv for w", "vvalter", "walter", true},
+		{"different names", "alice", "bob", false},
+		{"lowercase i and l", "admin", "admln", false},
+		{"Greek lowercase nu is v", "νictor", "nictor", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := UsernameSkeleton(tt.a), UsernameSkeleton(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("UsernameSkeleton(%q) = %q, UsernameSkeleton(%q) = %q, want same = %v", tt.a, a, tt.b, b, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores objects as files below a directory.
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// Put writes the object to a temporary file first so readers never see a
+// partially written object.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	target, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(target)
+}
+
+// path maps key to a file below dir, refusing keys that would escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleaned)), nil
+}
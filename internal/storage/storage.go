@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Open when no object exists under the key.
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps user-supplied files such as avatars. Keys are slash
+// separated paths chosen by the caller.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every object whose key starts with prefix + "/".
+	DeletePrefix(ctx context.Context, prefix string) error
+}